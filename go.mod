@@ -3,11 +3,11 @@ module github.com/si3nloong/rm-go-client
 go 1.16
 
 require (
-	github.com/clbanning/mxj v1.8.4
 	github.com/dchest/uniuri v0.0.0-20200228104902-7aecb25e1fe5
 	github.com/opentracing/opentracing-go v1.2.0
 	github.com/stretchr/testify v1.7.5
 	github.com/tidwall/gjson v1.14.1
 	github.com/valyala/bytebufferpool v1.0.0
 	golang.org/x/oauth2 v0.0.0-20211104180415-d3ed0bb246c8
+	golang.org/x/time v0.3.0
 )
@@ -0,0 +1,31 @@
+package rm
+
+import (
+	"math"
+	"strconv"
+)
+
+// Amount : the amount in the smallest unit of the currency, e.g. cents for MYR,
+// it's marshalled as integer which is what RM expects. Prefer it over float
+// to avoid the rounding error.
+type Amount int64
+
+// FromRinggit : converts the amount in ringgit to cents, it's rounded to the nearest cent
+func FromRinggit(v float64) Amount {
+	return Amount(math.Round(v * 100))
+}
+
+// String : returns the amount in the major unit with 2 decimal places, e.g. "12.34"
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	cents := strconv.FormatInt(v%100, 10)
+	if len(cents) < 2 {
+		cents = "0" + cents
+	}
+	return sign + strconv.FormatInt(v/100, 10) + "." + cents
+}
@@ -0,0 +1,24 @@
+package rm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmount(t *testing.T) {
+	require.Equal(t, Amount(1999), FromRinggit(19.99))
+	require.Equal(t, Amount(30), FromRinggit(0.1+0.2))
+	require.Equal(t, Amount(-150), FromRinggit(-1.5))
+
+	require.Equal(t, "19.99", Amount(1999).String())
+	require.Equal(t, "0.05", Amount(5).String())
+	require.Equal(t, "-1.50", Amount(-150).String())
+
+	b, err := json.Marshal(struct {
+		Amount Amount `json:"amount"`
+	}{FromRinggit(27.5)})
+	require.NoError(t, err)
+	require.Equal(t, `{"amount":2750}`, string(b))
+}
@@ -0,0 +1,152 @@
+package rm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	jlog "github.com/opentracing/opentracing-go/log"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit breaker is open
+var ErrCircuitOpen = errors.New("rm: circuit breaker is open")
+
+// BreakerState :
+type BreakerState int
+
+// breaker states :
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+// Breaker : guards every attempt of the request to RM, a request is failed if it's
+// a network error or the status code is 5xx.
+type Breaker interface {
+	// Allow returns ErrCircuitOpen if the request shouldn't be sent
+	Allow() error
+	// Done records the outcome of the request allowed
+	Done(success bool)
+	State() BreakerState
+}
+
+// BreakerObserver : the MetricsObserver may implement it to record the state transitions of the breaker
+type BreakerObserver interface {
+	ObserveBreakerState(from, to BreakerState)
+}
+
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+var _ Breaker = (*circuitBreaker)(nil)
+
+// NewBreaker : returns the breaker which opens after `threshold` consecutive failures,
+// and fast-fails with ErrCircuitOpen until the cooldown, then it half-opens to let
+// a single request probe RM. The breaker closes if the probe succeeds, otherwise it opens again.
+func NewBreaker(threshold int, cooldown time.Duration) Breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+func (b *circuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *circuitBreaker) Done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.state = BreakerClosed
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (c *Client) breakerAllow(span opentracing.Span) error {
+	from := c.breaker.State()
+	err := c.breaker.Allow()
+	c.observeBreaker(span, from)
+	return err
+}
+
+func (c *Client) breakerDone(span opentracing.Span, success bool) {
+	from := c.breaker.State()
+	c.breaker.Done(success)
+	c.observeBreaker(span, from)
+}
+
+func (c *Client) observeBreaker(span opentracing.Span, from BreakerState) {
+	to := c.breaker.State()
+	if to == from {
+		return
+	}
+	span.LogFields(jlog.String("breaker", to.String()))
+	if o, ok := c.metrics.(BreakerObserver); ok {
+		o.ObserveBreakerState(from, to)
+	}
+}
+
+// isFailure reports whether the attempt counts as the failure of RM,
+// the cancellation of the caller doesn't while the timeout does.
+func isFailure(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(ctx.Err(), context.Canceled)
+	}
+	return res.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,81 @@
+package rm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(1630000000, 0)
+	b := NewBreaker(2, time.Minute).(*circuitBreaker)
+	b.now = func() time.Time { return now }
+
+	require.NoError(t, b.Allow())
+	b.Done(false)
+	require.Equal(t, BreakerClosed, b.State())
+	// the success resets the consecutive failures
+	require.NoError(t, b.Allow())
+	b.Done(true)
+	require.NoError(t, b.Allow())
+	b.Done(false)
+	require.Equal(t, BreakerClosed, b.State())
+	require.NoError(t, b.Allow())
+	b.Done(false)
+	require.Equal(t, BreakerOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// only a single probe is allowed after the cooldown
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	require.Equal(t, BreakerHalfOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	b.Done(false)
+	require.Equal(t, BreakerOpen, b.State())
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Done(true)
+	require.Equal(t, BreakerClosed, b.State())
+	require.NoError(t, b.Allow())
+}
+
+type breakerMetrics struct {
+	recordMetrics
+	transitions []BreakerState
+}
+
+func (m *breakerMetrics) ObserveBreakerState(from, to BreakerState) {
+	m.transitions = append(m.transitions, to)
+}
+
+func TestClientBreaker(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	metrics := new(breakerMetrics)
+	client := mockServerClient(srv)
+	client.metrics = metrics
+	client.breaker = NewBreaker(2, time.Minute)
+
+	_, err := client.GetStore(context.Background(), "1")
+	require.ErrorIs(t, err, ErrServiceUnavailable)
+	_, err = client.GetStore(context.Background(), "1")
+	require.ErrorIs(t, err, ErrServiceUnavailable)
+	_, err = client.GetStore(context.Background(), "1")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	require.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	require.Equal(t, []BreakerState{BreakerOpen}, metrics.transitions)
+	require.ErrorIs(t, metrics.recordMetrics[2].err, ErrCircuitOpen)
+}
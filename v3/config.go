@@ -0,0 +1,210 @@
+package rm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// environment variables of NewClientFromEnv :
+const (
+	EnvClientID     = "RM_CLIENT_ID"
+	EnvClientSecret = "RM_CLIENT_SECRET"
+	EnvPrivateKey   = "RM_PRIVATE_KEY"
+	EnvStoreID      = "RM_STORE_ID"
+	EnvSandbox      = "RM_SANDBOX"
+)
+
+// ConfigError : contains all the problems of the Config
+type ConfigError []error
+
+var _ error = (ConfigError)(nil)
+
+func (e ConfigError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = strings.TrimPrefix(err.Error(), "rm: ")
+	}
+	return fmt.Sprintf(errTemplate, "invalid config: "+strings.Join(msgs, "; "))
+}
+
+// Validate : checks the required fields and the keys, it returns ConfigError
+// describing all the problems. The PrivateKeyReader is never read here, since
+// it can only be read once by NewClientWithError.
+func (cfg Config) Validate() error {
+	var errs ConfigError
+	fromReader := len(cfg.PrivateKey) == 0 && cfg.PrivateKeyReader != nil
+	cfg, err := cfg.loadKeyFiles()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if strings.TrimSpace(cfg.ClientID) == "" {
+		errs = append(errs, errors.New("missing client id"))
+	}
+	if strings.TrimSpace(cfg.ClientSecret) == "" && cfg.TokenSource == nil {
+		errs = append(errs, errors.New("missing client secret"))
+	}
+
+	if len(cfg.PrivateKey) == 0 {
+		if !fromReader {
+			errs = append(errs, errors.New("missing private key"))
+		}
+	} else if block, _ := pem.Decode(cfg.PrivateKey); block == nil {
+		errs = append(errs, errors.New("invalid format of private key"))
+	} else if _, err := parsePrivateKey(block); err != nil {
+		errs = append(errs, fmt.Errorf("invalid private key: %w", err))
+	}
+
+	if len(cfg.NextPrivateKey) > 0 {
+		if block, _ := pem.Decode(cfg.NextPrivateKey); block == nil {
+			errs = append(errs, errors.New("invalid format of next private key"))
+		} else if _, err := parsePrivateKey(block); err != nil {
+			errs = append(errs, fmt.Errorf("invalid next private key: %w", err))
+		}
+	}
+
+	for _, key := range append([][]byte{cfg.PublicKey}, cfg.PublicKeys...) {
+		if len(key) == 0 {
+			continue
+		}
+		if _, err := parsePublicKey(key); err != nil {
+			errs = append(errs, fmt.Errorf("invalid public key: %w", err))
+		}
+	}
+
+	if cfg.SignType != 0 {
+		if _, ok := signTypes[cfg.SignType]; !ok {
+			errs = append(errs, fmt.Errorf("unsupported sign type %v", cfg.SignType))
+		}
+	}
+
+	for name, endpoint := range map[string]string{
+		"oauth endpoint": cfg.OAuthEndpoint,
+		"open endpoint":  cfg.OpenEndpoint,
+	} {
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid %s %q", name, endpoint))
+			continue
+		}
+		// sandbox credentials never work on production, and vice versa
+		if cfg.Sandbox && strings.HasSuffix(u.Host, "revenuemonster.my") && !strings.HasPrefix(u.Host, "sb-") {
+			errs = append(errs, fmt.Errorf("%s %q is a production endpoint while sandbox is enabled", name, endpoint))
+		}
+	}
+
+	if cfg.MaxRetries < 0 {
+		errs = append(errs, errors.New("max retries cannot be negative"))
+	}
+	if cfg.RequestTimeout < 0 {
+		errs = append(errs, errors.New("request timeout cannot be negative"))
+	}
+	if cfg.TLSConfig != nil && cfg.HTTPClient != nil {
+		errs = append(errs, errors.New("tls config cannot be used with http client"))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// loadKeys loads the keys from the reader or path if they're not provided,
+// the reader is drained so it must only be called once by NewClientWithError
+func (cfg Config) loadKeys() (Config, error) {
+	if len(cfg.PrivateKey) == 0 && cfg.PrivateKeyReader != nil {
+		pk, err := ioutil.ReadAll(cfg.PrivateKeyReader)
+		if err != nil {
+			return cfg, fmt.Errorf("rm: unable to read private key: %w", err)
+		}
+		cfg.PrivateKey = pk
+	}
+	// the reader can only be read once
+	cfg.PrivateKeyReader = nil
+	return cfg.loadKeyFiles()
+}
+
+// loadKeyFiles loads the keys from the path if they're not provided,
+// the private key path is ignored if there is the reader
+func (cfg Config) loadKeyFiles() (Config, error) {
+	var err error
+	if len(cfg.PrivateKey) == 0 && cfg.PrivateKeyReader == nil && cfg.PrivateKeyPath != "" {
+		cfg.PrivateKey, err = ioutil.ReadFile(cfg.PrivateKeyPath)
+		if err != nil {
+			return cfg, fmt.Errorf("rm: unable to read private key: %w", err)
+		}
+	}
+	if len(cfg.PublicKey) == 0 && cfg.PublicKeyPath != "" {
+		cfg.PublicKey, err = ioutil.ReadFile(cfg.PublicKeyPath)
+		if err != nil {
+			return cfg, fmt.Errorf("rm: unable to read public key: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// NewClientFromEnv : create a new client from the environment variables RM_CLIENT_ID,
+// RM_CLIENT_SECRET, RM_PRIVATE_KEY, RM_STORE_ID and RM_SANDBOX. The private key is
+// either the PEM or the base64 encoded PEM, since the newlines are awkward in env vars.
+func NewClientFromEnv() (*Client, error) {
+	cfg, err := configFromEnv(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientWithError(cfg)
+}
+
+func configFromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		ClientID:     strings.TrimSpace(getenv(EnvClientID)),
+		ClientSecret: strings.TrimSpace(getenv(EnvClientSecret)),
+		StoreID:      strings.TrimSpace(getenv(EnvStoreID)),
+	}
+
+	if v := strings.TrimSpace(getenv(EnvSandbox)); v != "" {
+		sandbox, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("rm: invalid %s %q", EnvSandbox, v)
+		}
+		cfg.Sandbox = sandbox
+	}
+
+	pk, err := decodeEnvPEM(getenv(EnvPrivateKey))
+	if err != nil {
+		return cfg, fmt.Errorf("rm: invalid %s: %w", EnvPrivateKey, err)
+	}
+	cfg.PrivateKey = pk
+	return cfg, nil
+}
+
+// decodeEnvPEM returns the PEM as is, or decodes the base64 encoded PEM.
+// The escaped newlines, e.g. "\n" of the dotenv file, are unescaped as well.
+func decodeEnvPEM(v string) ([]byte, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(v, "-----BEGIN") {
+		return []byte(strings.ReplaceAll(v, `\n`, "\n")), nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(v), ""))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN")) {
+		return nil, errors.New("base64 decoded value is not a PEM")
+	}
+	return b, nil
+}
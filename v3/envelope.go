@@ -0,0 +1,111 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// envelope is the response envelope of RM, the object is wrapped in `item`
+// while the list is wrapped in `items` along with `pagination` or `meta`
+type envelope struct {
+	Code       string          `json:"code"`
+	Item       json.RawMessage `json:"item"`
+	Items      json.RawMessage `json:"items"`
+	Pagination Pagination      `json:"pagination"`
+	Meta       struct {
+		Count int `json:"count"`
+		Total int `json:"total"`
+	} `json:"meta"`
+}
+
+// page returns the pagination of the list, the offset and limit requested
+// are used if RM doesn't echo them
+func (env *envelope) page(offset, limit int) Pagination {
+	page := env.Pagination
+	if page.Total == 0 {
+		page.Total = env.Meta.Total
+	}
+	if page.Offset == 0 {
+		page.Offset = offset
+	}
+	if page.Limit == 0 {
+		page.Limit = limit
+	}
+	return page
+}
+
+// doUnwrap is the same as do, but it unwraps the `item` or `items` of the envelope into dest
+// and returns the envelope for the metadata such as code and pagination
+func (c *Client) doUnwrap(
+	ctx context.Context,
+	operationName string,
+	method string,
+	endpoint string,
+	src interface{},
+	dest interface{},
+	opts ...RequestOption,
+) (*envelope, error) {
+	env := new(envelope)
+	if err := c.do(ctx, operationName, method, endpoint, src, env, opts...); err != nil {
+		return nil, err
+	}
+	if err := env.unwrap(dest); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// unwrap decodes the `item` or `items` into dest, it's skipped if dest is nil
+func (env *envelope) unwrap(dest interface{}) error {
+	raw := env.Item
+	if len(raw) == 0 {
+		raw = env.Items
+	}
+	if len(raw) == 0 || dest == nil {
+		return nil
+	}
+	return unmarshalJSON(raw, dest)
+}
+
+// DecodeResponse : unwraps the `item` or `items` of the response body into dest, e.g.
+// the body returned by DoRaw, and returns the pagination of the list. The envelope is
+// shared by every endpoint, so the typed methods are the thin wrappers of the same decoding.
+func DecodeResponse(b []byte, dest interface{}) (Pagination, error) {
+	env := new(envelope)
+	if err := unmarshalJSON(b, env); err != nil {
+		return Pagination{}, err
+	}
+	if err := env.unwrap(dest); err != nil {
+		return Pagination{}, err
+	}
+	return env.page(0, 0), nil
+}
+
+// list requests a page of the list endpoint and decodes the `items` into dest,
+// offset and limit of params are set by the page requested
+func (c *Client) list(
+	ctx context.Context,
+	operationName string,
+	endpoint string,
+	params url.Values,
+	offset, limit int,
+	dest interface{},
+	opts ...RequestOption,
+) (Pagination, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(limit))
+
+	env, err := c.doUnwrap(ctx, operationName, "get", endpoint+"?"+params.Encode(), nil, dest, opts...)
+	if err != nil {
+		return Pagination{}, err
+	}
+	return env.page(offset, limit), nil
+}
@@ -1,7 +1,9 @@
 package rm
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/tidwall/gjson"
@@ -17,6 +19,17 @@ const (
 	ErrorCodeValidationError                  = "VALIDATION_ERROR"
 	ErrorCodeRefundAmountExceedPerDay         = "PAYMENT_REFUND_AMOUNT_EXCEED_PER_DAY"
 	ErrorCodeMerchantSettlementAccNotVerified = "MERCHANT_SETTLEMENT_ACCOUNT_NOT_VERIFIED"
+	ErrorCodeTransactionAlreadySettled        = "TRANSACTION_ALREADY_SETTLED"
+	ErrorCodeVoucherAlreadyRedeemed           = "VOUCHER_ALREADY_REDEEMED"
+	ErrorCodeVoucherExpired                   = "VOUCHER_EXPIRED"
+	ErrorCodeServiceUnavailable               = "SERVICE_UNAVAILABLE"
+	ErrorCodeQRAlreadyPaid                    = "QR_CODE_ALREADY_PAID"
+	ErrorCodeQRAlreadyCancelled               = "QR_CODE_ALREADY_CANCELLED"
+	ErrorCodeInsufficientBalance              = "INSUFFICIENT_BALANCE"
+	ErrorCodePaymentAlreadyCaptured           = "PAYMENT_ALREADY_CAPTURED"
+	ErrorCodeCaptureAmountExceedAuthorized    = "CAPTURE_AMOUNT_EXCEED_AUTHORIZED_AMOUNT"
+	ErrorCodeSignatureMismatch                = "SIGNATURE_MISMATCH"
+	ErrorCodeDuplicateOrderID                 = "DUPLICATE_ORDER_ID"
 )
 
 // error to compare, you may compare using errors.Is(err, rm.ErrPaymentAlreadyRefunded)
@@ -25,10 +38,38 @@ var (
 	ErrTransactionNotFound     = newErrorCode(ErrorCodeTransactionNotFound)
 	ErrStoreNotFound           = newErrorCode(ErrorCodeStoreNotFound)
 	ErrRefundExceedLimitPerDay = newErrorCode(ErrorCodeRefundAmountExceedPerDay)
-	ErrValidation              = newErrorCode(ErrorCodeValidationError)
+	ErrValidation              = newStatusErrorCode(ErrorCodeValidationError, http.StatusUnprocessableEntity)
+	ErrAlreadySettled          = newErrorCode(ErrorCodeTransactionAlreadySettled)
+	ErrVoucherAlreadyRedeemed  = newErrorCode(ErrorCodeVoucherAlreadyRedeemed)
+	ErrVoucherExpired          = newErrorCode(ErrorCodeVoucherExpired)
+	ErrSignatureMismatch       = newErrorCode(ErrorCodeSignatureMismatch)
+	ErrQRAlreadyPaid           = newErrorCode(ErrorCodeQRAlreadyPaid)
+	ErrQRAlreadyCancelled      = newErrorCode(ErrorCodeQRAlreadyCancelled)
+	// ErrInsufficientBalance is returned when the settled balance of the merchant
+	// isn't enough for the refund, the refund may be retried once the balance is settled
+	ErrInsufficientBalance = newErrorCode(ErrorCodeInsufficientBalance)
+	ErrAlreadyCaptured     = newErrorCode(ErrorCodePaymentAlreadyCaptured)
+	ErrCaptureExceedsAuth  = newErrorCode(ErrorCodeCaptureAmountExceedAuthorized)
+	// ErrDuplicateOrder is returned when the order id is reused, APIError.TransactionID
+	// is the existing transaction if RM returns it, otherwise use GetTransactionByOrderID
+	ErrDuplicateOrder = newErrorCode(ErrorCodeDuplicateOrderID)
 )
 
-type errorCode struct{ id string }
+// error categories, they're matched by the status code as well as the error code,
+// e.g. errors.Is(err, rm.ErrRateLimited)
+var (
+	ErrUnauthorized = newStatusErrorCode("UNAUTHORIZED", http.StatusUnauthorized)
+	ErrNotFound     = newStatusErrorCode("NOT_FOUND", http.StatusNotFound)
+	ErrRateLimited  = newStatusErrorCode("TOO_MANY_REQUESTS", http.StatusTooManyRequests)
+	ErrBadGateway   = newStatusErrorCode("BAD_GATEWAY", http.StatusBadGateway)
+	// ErrServiceUnavailable is returned when RM is under maintenance
+	ErrServiceUnavailable = newStatusErrorCode(ErrorCodeServiceUnavailable, http.StatusServiceUnavailable)
+)
+
+type errorCode struct {
+	id     string
+	status int
+}
 
 var (
 	_ error     = (*errorCode)(nil)
@@ -39,10 +80,19 @@ func newErrorCode(id string) error {
 	return &errorCode{id: id}
 }
 
+// newStatusErrorCode returns the error which also matches the status code
+func newStatusErrorCode(id string, status int) error {
+	return &errorCode{id: id, status: status}
+}
+
 func (err errorCode) isCode(id string) bool {
 	return err.id == id
 }
 
+func (err errorCode) isStatus(status int) bool {
+	return err.status != 0 && err.status == status
+}
+
 func (err errorCode) Error() string {
 	return fmt.Sprintf(errTemplate, err.id)
 }
@@ -51,65 +101,145 @@ type ErrorCode interface {
 	isCode(id string) bool
 }
 
-// Error :
-type Error struct {
-	code        string
-	url         string
-	rawRequest  []byte
-	rawResponse []byte
+// APIError : the error returned by RM, you may retrieve it using errors.As(err, &apiErr)
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestURL string
+	// RequestID is the correlation id returned by RM, quote it when contacting RM support
+	RequestID string
+	// TransactionID is the transaction related to the error, e.g. the existing
+	// transaction of ErrDuplicateOrder
+	TransactionID string
+	Raw           []byte
+	rawRequest    []byte
 }
 
+// Error : alias of APIError for backward compatibility
+type Error = APIError
+
 var (
-	_ fmt.Formatter = (*Error)(nil)
-	_ error         = (*Error)(nil)
+	_ fmt.Formatter = (*APIError)(nil)
+	_ error         = (*APIError)(nil)
 )
 
-func newError(url string, reqBytes, respBytes []byte) *Error {
-	e := new(Error)
-	e.code = strings.ToUpper(strings.TrimSpace(gjson.GetBytes(respBytes, "error.code").String()))
-	e.url = url
-	e.rawResponse = respBytes
+func newError(statusCode int, url string, reqBytes, respBytes []byte) *APIError {
+	e := new(APIError)
+	e.StatusCode = statusCode
+	if gjson.ValidBytes(respBytes) {
+		e.Code = strings.ToUpper(strings.TrimSpace(gjson.GetBytes(respBytes, "error.code").String()))
+		e.Message = gjson.GetBytes(respBytes, "error.message").String()
+		for _, path := range []string{"error.transactionId", "error.data.transactionId"} {
+			if v := gjson.GetBytes(respBytes, path).String(); v != "" {
+				e.TransactionID = v
+				break
+			}
+		}
+	} else {
+		// the body isn't the error envelope, e.g. the HTML page of the maintenance
+		e.Message = snippet(respBytes)
+		if statusCode == http.StatusServiceUnavailable {
+			e.Code = ErrorCodeServiceUnavailable
+		}
+	}
+	e.RequestURL = url
+	e.Raw = respBytes
 	e.rawRequest = reqBytes
 	return e
 }
 
-func (e Error) isCode(errID string) bool {
-	return e.code == errID
+// maxSnippetLen is the maximum length of the non-JSON body kept in the message
+const maxSnippetLen = 200
+
+func snippet(b []byte) string {
+	s := strings.Join(strings.Fields(string(b)), " ")
+	if len(s) > maxSnippetLen {
+		s = s[:maxSnippetLen] + "..."
+	}
+	return s
 }
 
-func (e Error) Is(err error) bool {
+// requestID returns the correlation id from the response header
+func requestID(header http.Header) string {
+	for _, k := range []string{"X-Request-Id", "Request-Id", "X-Correlation-Id"} {
+		if v := header.Get(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (e APIError) isCode(errID string) bool {
+	return e.Code == errID
+}
+
+func (e APIError) Is(err error) bool {
+	if v, ok := err.(interface{ isStatus(int) bool }); ok && v.isStatus(e.StatusCode) {
+		return true
+	}
 	v, ok := err.(ErrorCode)
 	if ok {
-		return v.isCode(e.code)
+		return v.isCode(e.Code)
 	}
 	return e.Error() == err.Error()
 }
 
 // Error :
-func (e Error) Error() string {
-	return fmt.Sprintf(errTemplate, e.code)
+func (e APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf(errTemplate, fmt.Sprintf("unexpected status code %d", e.StatusCode))
+	}
+	return fmt.Sprintf(errTemplate, e.Code)
 }
 
-func (e Error) Format(f fmt.State, verb rune) {
+func (e APIError) Format(f fmt.State, verb rune) {
 	if !f.Flag('+') {
 		f.Write([]byte(e.Error()))
 		return
 	}
 
 	f.Write([]byte("URL : "))
-	f.Write([]byte(e.url))
+	f.Write([]byte(e.RequestURL))
 	f.Write([]byte("\n"))
+	if e.RequestID != "" {
+		f.Write([]byte("Request ID : "))
+		f.Write([]byte(e.RequestID))
+		f.Write([]byte("\n"))
+	}
 	f.Write([]byte("Request Body :\n"))
 	f.Write(e.rawRequest)
 	f.Write([]byte("\n"))
 	f.Write([]byte("Response Body :\n"))
-	f.Write(e.rawResponse)
+	f.Write(e.Raw)
+}
+
+func (e APIError) Response() string {
+	return string(e.Raw)
+}
+
+func (e APIError) ResponseBytes() []byte {
+	return e.Raw
 }
 
-func (e Error) Response() string {
-	return string(e.rawResponse)
+// ErrDryRun is matched by DryRunError, e.g. errors.Is(err, rm.ErrDryRun)
+var ErrDryRun = errors.New("rm: dry run")
+
+// DryRunError : is returned by the request of dry run, it contains the request
+// which would be sent, it's signed but without the Authorization header.
+type DryRunError struct {
+	Request *http.Request
+	// Body is the request body sent and signed, it's nil if there is no body
+	Body []byte
+}
+
+var _ error = (*DryRunError)(nil)
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf(errTemplate, "dry run "+e.Request.Method+" "+e.Request.URL.String())
 }
 
-func (e Error) ResponseBytes() []byte {
-	return e.rawResponse
+// Is :
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
 }
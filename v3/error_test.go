@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"strings"
 	"testing"
 
@@ -21,7 +22,7 @@ func TestRmError(t *testing.T) {
 	b, err := ioutil.ReadFile("./sample/validation_error.json")
 	require.NoError(t, err)
 
-	rmErr := newError("http://google.com", nil, b)
+	rmErr := newError(http.StatusUnprocessableEntity, "http://google.com", nil, b)
 	require.Error(t, rmErr)
 	require.Contains(t, fmt.Sprintf("%+v", rmErr), string(b))
 	require.Implements(t, (*ErrorCode)(nil), rmErr)
@@ -33,4 +34,48 @@ func TestRmError(t *testing.T) {
 	require.True(t, errors.Is(fmt.Errorf("wrap: %w", rmErr), ErrValidation))
 	require.Equal(t, string(b), rmErr.Response())
 	require.Equal(t, b, rmErr.ResponseBytes())
+
+	var apiErr *APIError
+	require.True(t, errors.As(fmt.Errorf("wrap: %w", rmErr), &apiErr))
+	require.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	require.Equal(t, ErrorCodeValidationError, apiErr.Code)
+	require.Equal(t, "Validations error", apiErr.Message)
+	require.Equal(t, "http://google.com", apiErr.RequestURL)
+	require.Equal(t, b, apiErr.Raw)
+}
+
+func TestErrorCategory(t *testing.T) {
+	for status, sentinel := range map[int]error{
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusNotFound:            ErrNotFound,
+		http.StatusTooManyRequests:     ErrRateLimited,
+		http.StatusBadGateway:          ErrBadGateway,
+		http.StatusUnprocessableEntity: ErrValidation,
+	} {
+		err := fmt.Errorf("wrap: %w", newError(status, "http://google.com", nil, nil))
+		require.True(t, errors.Is(err, sentinel), "status %d", status)
+		require.False(t, errors.Is(err, ErrStoreNotFound))
+	}
+
+	// the category is also matched by the error code
+	rmErr := newError(http.StatusBadRequest, "http://google.com", nil, []byte(`{"error":{"code":"VALIDATION_ERROR"}}`))
+	require.True(t, errors.Is(rmErr, ErrValidation))
+	require.False(t, errors.Is(rmErr, ErrNotFound))
+
+	rmErr = newError(http.StatusNotFound, "http://google.com", nil, []byte(`{"error":{"code":"STORE_NOT_FOUND"}}`))
+	require.True(t, errors.Is(rmErr, ErrNotFound))
+	require.True(t, errors.Is(rmErr, ErrStoreNotFound))
+}
+
+func TestMaintenanceError(t *testing.T) {
+	html := []byte("<html>\n  <body>\n    <h1>Scheduled maintenance</h1>\n  </body>\n</html>")
+	rmErr := newError(http.StatusServiceUnavailable, "http://google.com", nil, html)
+	require.True(t, errors.Is(rmErr, ErrServiceUnavailable))
+	require.Equal(t, ErrorCodeServiceUnavailable, rmErr.Code)
+	require.Equal(t, "<html> <body> <h1>Scheduled maintenance</h1> </body> </html>", rmErr.Message)
+	require.Equal(t, html, rmErr.Raw)
+
+	rmErr = newError(http.StatusInternalServerError, "http://google.com", nil, []byte(strings.Repeat("x", 500)))
+	require.Empty(t, rmErr.Code)
+	require.Len(t, rmErr.Message, maxSnippetLen+3)
 }
@@ -0,0 +1,119 @@
+package rm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// generateKey returns the PEM of a new private key and its public key
+func generateKey(t *testing.T) ([]byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+}
+
+func TestNextPrivateKey(t *testing.T) {
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	nextPK, nextPubPEM := generateKey(t)
+
+	var (
+		hits        int32
+		acceptsNext int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		keys := [][]byte{pubPEM}
+		if atomic.LoadInt32(&acceptsNext) == 1 {
+			keys = append(keys, nextPubPEM)
+		}
+
+		endpoint := "http://" + r.Host + r.URL.RequestURI()
+		for _, key := range keys {
+			pub, _ := parsePublicKey(key)
+			if verifySignature(r.Header, pub, func(signType string) []string {
+				return signParams("", "get", endpoint, r.Header.Get("X-Nonce-Str"), r.Header.Get("X-Timestamp"), signType)
+			}) == nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"code":"SIGNATURE_MISMATCH","message":"Signature mismatch"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(Config{
+		ClientID:       "xxx",
+		ClientSecret:   "xxx",
+		PrivateKey:     pk,
+		NextPrivateKey: nextPK,
+		TokenSource:    oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}),
+		OpenEndpoint:   srv.URL,
+	})
+
+	// RM doesn't have the next key yet
+	_, err := client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&hits))
+
+	atomic.StoreInt32(&acceptsNext, 1)
+	atomic.StoreInt32(&hits, 0)
+	_, err = client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	_, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: pk, NextPrivateKey: []byte("invalid")})
+	require.Error(t, err)
+}
+
+func TestVerifyResponseKeys(t *testing.T) {
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	serverPub, _ := ioutil.ReadFile("../test/server_pub.pem")
+	nextPK, nextPub := generateKey(t)
+
+	client := NewClient(Config{
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		PublicKey:    serverPub,
+		PublicKeys:   [][]byte{nextPub},
+	})
+
+	var (
+		endpoint = "https://sb-open.revenuemonster.my/v3/stores"
+		body     = []byte(`{"code":"SUCCESS","items":[]}`)
+	)
+	block, _ := pem.Decode(nextPK)
+	key, err := parsePrivateKey(block)
+	require.NoError(t, err)
+	sign, err := signData(crypto.SHA256, signParams(base64.StdEncoding.EncodeToString(body), "get", endpoint, "nonce", "1630000000", "sha256"), key)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Nonce-Str", "nonce")
+	header.Set("X-Timestamp", "1630000000")
+	header.Set("X-Signature", "sha256 "+sign)
+	// signed by the next key of RM
+	require.NoError(t, client.verifyResponse("get", endpoint, header, body))
+
+	client.pubs = nil
+	require.ErrorIs(t, client.verifyResponse("get", endpoint, header, body), ErrSignatureMismatch)
+}
@@ -0,0 +1,68 @@
+package rm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// PaymentLinks : the links of the payment returned by RM, the absent links are nil
+type PaymentLinks struct {
+	// URL is the web checkout page
+	URL *url.URL
+	// QRCodeURL is the url encoded in the QR code
+	QRCodeURL *url.URL
+	// DeepLink opens the wallet app of the payment method, e.g. "tngd://..."
+	DeepLink *url.URL
+}
+
+// ParsePaymentLinks : parses the `url`, `qrCodeUrl` and `deeplink` of the response body,
+// either the envelope or the item, and validates they're well-formed.
+// The web and QR code urls must be http(s), while the deep link can be any scheme.
+func ParsePaymentLinks(b []byte) (PaymentLinks, error) {
+	item := gjson.ParseBytes(b)
+	if v := item.Get("item"); v.IsObject() {
+		item = v
+	}
+	return newPaymentLinks(
+		item.Get("url").String(),
+		item.Get("qrCodeUrl").String(),
+		item.Get("deeplink").String(),
+	)
+}
+
+func newPaymentLinks(webURL, qrCodeURL, deepLink string) (PaymentLinks, error) {
+	var (
+		links PaymentLinks
+		err   error
+	)
+	if links.URL, err = parseLink("url", webURL, true); err != nil {
+		return PaymentLinks{}, err
+	}
+	if links.QRCodeURL, err = parseLink("qrCodeUrl", qrCodeURL, true); err != nil {
+		return PaymentLinks{}, err
+	}
+	if links.DeepLink, err = parseLink("deeplink", deepLink, false); err != nil {
+		return PaymentLinks{}, err
+	}
+	return links, nil
+}
+
+func parseLink(name, link string, web bool) (*url.URL, error) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("rm: invalid %s %q: %w", name, link, err)
+	}
+	if u.Scheme == "" ||
+		(web && ((u.Scheme != "http" && u.Scheme != "https") || u.Host == "")) {
+		return nil, fmt.Errorf("rm: invalid %s %q", name, link)
+	}
+	return u, nil
+}
@@ -0,0 +1,40 @@
+package rm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaymentLinks(t *testing.T) {
+	links, err := ParsePaymentLinks([]byte(`{"item":{
+		"checkoutId":"1234",
+		"url":"https://sb-pg.revenuemonster.my/checkout?checkoutId=1234",
+		"deeplink":"tngd://payment?checkoutId=1234"
+	},"code":"SUCCESS"}`))
+	require.NoError(t, err)
+	require.Equal(t, "sb-pg.revenuemonster.my", links.URL.Host)
+	require.Equal(t, "tngd", links.DeepLink.Scheme)
+	require.Nil(t, links.QRCodeURL)
+
+	links, err = ParsePaymentLinks([]byte(`{"qrCodeUrl":"https://sb-api.revenuemonster.my/qr/1234"}`))
+	require.NoError(t, err)
+	require.Equal(t, "/qr/1234", links.QRCodeURL.Path)
+
+	for _, body := range []string{
+		`{"item":{"url":"/checkout?checkoutId=1234"}}`,
+		`{"item":{"url":"tngd://payment"}}`,
+		`{"item":{"qrCodeUrl":"http://%zz"}}`,
+		`{"item":{"deeplink":"payment?checkoutId=1234"}}`,
+	} {
+		_, err := ParsePaymentLinks([]byte(body))
+		require.Error(t, err, body)
+	}
+
+	resp := CreatePaymentCheckoutResponse{}
+	resp.Item.URL = "https://sb-pg.revenuemonster.my/checkout?checkoutId=1234"
+	links, err = resp.Links()
+	require.NoError(t, err)
+	require.Equal(t, "checkoutId=1234", links.URL.RawQuery)
+	require.Nil(t, links.DeepLink)
+}
@@ -0,0 +1,16 @@
+package rm
+
+// Logger : the structured logger, keysAndValues are the alternating key and value pairs,
+// e.g. "method", "get", "status", 200
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+type nopLogger struct{}
+
+var _ Logger = (*nopLogger)(nil)
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
@@ -0,0 +1,87 @@
+package rm
+
+import (
+	"context"
+	"net/url"
+)
+
+// PointType : determine how the `UserID` of GrantPointRequest is identified
+type PointType string
+
+// point types :
+const (
+	PointTypeMemberID    PointType = "ID"
+	PointTypePhoneNumber PointType = "PHONENUMBER"
+)
+
+// GrantPointRequest :
+type GrantPointRequest struct {
+	UserID string    `json:"memberId"`
+	Point  uint      `json:"point"`
+	Type   PointType `json:"type"`
+	Remark string    `json:"remark,omitempty"`
+}
+
+// PointResponse :
+type PointResponse struct {
+	Item struct {
+		ID        string    `json:"id"`
+		MemberID  string    `json:"memberId"`
+		Point     uint      `json:"point"`
+		Type      PointType `json:"type"`
+		Remark    string    `json:"remark"`
+		CreatedAt Time      `json:"createdAt"`
+	} `json:"item"`
+	Code string `json:"code"`
+}
+
+// GrantLoyaltyPoint :
+func (c *Client) GrantLoyaltyPoint(
+	ctx context.Context,
+	req GrantPointRequest,
+) (*PointResponse, error) {
+	if req.Type == "" {
+		req.Type = PointTypeMemberID
+	}
+
+	resp := new(PointResponse)
+	if err := c.do(
+		ctx,
+		"grant_loyalty_point",
+		"post",
+		c.openURL("/v3/loyalty/reward"),
+		req,
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PointBalance :
+type PointBalance struct {
+	MemberID    string `json:"memberId"`
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phoneNumber"`
+	Point       uint   `json:"loyaltyPoint"`
+	UpdatedAt   Time   `json:"updatedAt"`
+}
+
+// GetMemberPointBalance :
+func (c *Client) GetMemberPointBalance(
+	ctx context.Context,
+	userID string,
+) (*PointBalance, error) {
+	item := new(PointBalance)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_member_point_balance",
+		"get",
+		c.openURL("/v3/loyalty/member/"+url.PathEscape(userID)+"/point"),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
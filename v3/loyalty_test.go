@@ -0,0 +1,47 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoyalty(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/v3/loyalty/reward":
+			req := GrantPointRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, PointTypeMemberID, req.Type)
+			w.Write([]byte(`{"item":{"id":"1","memberId":"` + req.UserID + `","point":100,"type":"ID"},"code":"SUCCESS"}`))
+		case "/v3/loyalty/member/123/point":
+			w.Write([]byte(`{"item":{"memberId":"123","loyaltyPoint":250},"code":"SUCCESS"}`))
+		case "/v3/loyalty/member/a%2Fb/point":
+			w.Write([]byte(`{"item":{"memberId":"a/b","loyaltyPoint":10},"code":"SUCCESS"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+
+	resp, err := client.GrantLoyaltyPoint(ctx, GrantPointRequest{UserID: "123", Point: 100, Remark: "Testing"})
+	require.NoError(t, err)
+	require.Equal(t, "123", resp.Item.MemberID)
+	require.Equal(t, uint(100), resp.Item.Point)
+
+	balance, err := client.GetMemberPointBalance(ctx, "123")
+	require.NoError(t, err)
+	require.Equal(t, uint(250), balance.Point)
+
+	balance, err = client.GetMemberPointBalance(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, uint(10), balance.Point)
+}
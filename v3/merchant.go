@@ -0,0 +1,43 @@
+package rm
+
+import (
+	"context"
+)
+
+// Merchant :
+type Merchant struct {
+	ID                 string `json:"id"`
+	CompanyName        string `json:"companyName"`
+	CompanyType        string `json:"companyType"`
+	CompanyLogoURL     string `json:"companyLogoUrl"`
+	RegistrationNumber string `json:"registrationNumber"`
+	BusinessCategory   string `json:"businessCategory"`
+	BusinessScope      string `json:"businessScope"`
+	AddressLine1       string `json:"addressLine1"`
+	AddressLine2       string `json:"addressLine2"`
+	PostCode           string `json:"postCode"`
+	City               string `json:"city"`
+	State              string `json:"state"`
+	Country            string `json:"country"`
+	CountryCode        string `json:"countryCode"`
+	PhoneNumber        string `json:"phoneNumber"`
+	Status             string `json:"status"`
+	CreatedAt          Time   `json:"createdAt"`
+	UpdatedAt          Time   `json:"updatedAt"`
+}
+
+// GetMerchantProfile : returns the profile of the authenticated merchant
+func (c *Client) GetMerchantProfile(ctx context.Context) (*Merchant, error) {
+	item := new(Merchant)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_merchant_profile",
+		"get",
+		c.openURL("/v3/merchant"),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,32 @@
+package rm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMerchantProfile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/v3/merchant", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"id":"1","companyName":"RM Sdn Bhd","companyType":"SDN_BHD","registrationNumber":"123456-A","businessCategory":"RETAIL","city":"Kuala Lumpur","countryCode":"60","status":"ACTIVE","createdAt":"2021-03-01T00:00:00Z"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	merchant, err := mockServerClient(srv).GetMerchantProfile(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1", merchant.ID)
+	require.Equal(t, "RM Sdn Bhd", merchant.CompanyName)
+	require.Equal(t, "SDN_BHD", merchant.CompanyType)
+	require.Equal(t, "123456-A", merchant.RegistrationNumber)
+	require.Equal(t, "RETAIL", merchant.BusinessCategory)
+	require.Equal(t, "Kuala Lumpur", merchant.City)
+	require.Equal(t, "60", merchant.CountryCode)
+	require.Equal(t, "ACTIVE", merchant.Status)
+	require.Equal(t, 2021, merchant.CreatedAt.Year())
+}
@@ -0,0 +1,17 @@
+package rm
+
+import "time"
+
+// MetricsObserver : receives the outcome of every RM request, it's useful to export
+// the Prometheus metrics such as request count, latency and error rate.
+// statusCode is zero if no response is received, e.g. network error.
+type MetricsObserver interface {
+	ObserveRequest(operation string, statusCode int, duration time.Duration, err error)
+}
+
+type nopMetrics struct{}
+
+var _ MetricsObserver = (*nopMetrics)(nil)
+
+func (nopMetrics) ObserveRequest(operation string, statusCode int, duration time.Duration, err error) {
+}
@@ -0,0 +1,60 @@
+package rm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// NotifyConfig : the notification (webhook) url of the merchant
+type NotifyConfig struct {
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	UpdatedAt Time     `json:"updatedAt"`
+}
+
+// SetNotifyURL : registers the url to be notified on the events, e.g. "PAYMENT_WEB_ONLINE",
+// the url must be the absolute http or https url
+func (c *Client) SetNotifyURL(ctx context.Context, events []string, notifyURL string) error {
+	if notifyURL == "" {
+		return errors.New("rm: notify url is required")
+	}
+	if u, err := url.Parse(notifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("rm: invalid notify url %q", notifyURL)
+	}
+	if events == nil {
+		events = make([]string, 0)
+	}
+
+	if _, err := c.doUnwrap(
+		ctx,
+		"set_notify_url",
+		"put",
+		c.openURL("/v3/merchant/notify-url"),
+		struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+		}{notifyURL, events},
+		nil,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetNotifyConfig :
+func (c *Client) GetNotifyConfig(ctx context.Context) (*NotifyConfig, error) {
+	item := new(NotifyConfig)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_notify_config",
+		"get",
+		c.openURL("/v3/merchant/notify-url"),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,52 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyURL(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		require.Equal(t, "/v3/merchant/notify-url", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodPut:
+			body := map[string]interface{}{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, map[string]interface{}{
+				"url":    "https://example.com/webhook",
+				"events": []interface{}{"PAYMENT_WEB_ONLINE"},
+			}, body)
+			w.Write([]byte(`{"code":"SUCCESS"}`))
+		case http.MethodGet:
+			w.Write([]byte(`{"item":{"url":"https://example.com/webhook","events":["PAYMENT_WEB_ONLINE"],"updatedAt":"2021-03-01T00:00:00Z"},"code":"SUCCESS"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	require.NoError(t, client.SetNotifyURL(ctx, []string{"PAYMENT_WEB_ONLINE"}, "https://example.com/webhook"))
+
+	// the invalid url never reaches RM
+	for _, u := range []string{"", "example.com/webhook", "ftp://example.com", "https://"} {
+		require.Error(t, client.SetNotifyURL(ctx, nil, u), u)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	cfg, err := client.GetNotifyConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/webhook", cfg.URL)
+	require.Equal(t, []string{"PAYMENT_WEB_ONLINE"}, cfg.Events)
+	require.Equal(t, 2021, cfg.UpdatedAt.Year())
+}
@@ -2,11 +2,13 @@ package rm
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -14,7 +16,10 @@ import (
 
 // GetAccessTokenRequest :
 type GetAccessTokenRequest struct {
-	GrantType string `json:"grantType"`
+	GrantType    string `json:"grantType"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	Code         string `json:"code,omitempty"`
+	RedirectURI  string `json:"redirectUri,omitempty"`
 }
 
 // GetAccessTokenResponse :
@@ -24,65 +29,324 @@ type GetAccessTokenResponse struct {
 	ExpiresIn             int    `json:"expiresIn"`
 	RefreshToken          string `json:"refreshToken"`
 	RefreshTokenExpiresIn int    `json:"refreshTokenExpiresIn"`
+	Scope                 Scopes `json:"scope"`
 }
 
+// Scopes : the scopes granted to the token, RM returns either the space-delimited string or the array
+type Scopes []string
+
+// UnmarshalJSON :
+func (s *Scopes) UnmarshalJSON(b []byte) error {
+	var scopes []string
+	if err := json.Unmarshal(b, &scopes); err == nil {
+		*s = scopes
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = strings.Fields(str)
+	return nil
+}
+
+// grant types :
+const (
+	grantTypeClientCredentials = "client_credentials"
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+)
+
+// tokenRefresher is the token source which can invalidate its cached token, e.g. Client
+type tokenRefresher interface {
+	ForceTokenRefresh(ctx context.Context) (*oauth2.Token, error)
+}
+
+var _ tokenRefresher = (*Client)(nil)
+
+// tokenExpiryDelta is how early the cached token is considered expired
+const tokenExpiryDelta = 60 * time.Second
+
+// contextTokenSource is the token source which requests the token within the ctx, e.g. Client
+type contextTokenSource interface {
+	tokenContext(ctx context.Context) (*oauth2.Token, error)
+}
+
+var _ contextTokenSource = (*Client)(nil)
+
+// tokenOf returns the token of the source bounded by the ctx. The source which
+// doesn't take the ctx, e.g. Config.TokenSource, keeps running in the background
+// after the ctx is done, but the caller doesn't wait for it.
+func tokenOf(ctx context.Context, src oauth2.TokenSource) (*oauth2.Token, error) {
+	if s, ok := src.(contextTokenSource); ok {
+		return s.tokenContext(ctx)
+	}
+	if ctx.Done() == nil {
+		return src.Token()
+	}
+
+	type result struct {
+		tkn *oauth2.Token
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		tkn, err := src.Token()
+		ch <- result{tkn, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.tkn, r.err
+	}
+}
+
+// Token : returns the cached token, the token is only requested when it's about to expire.
+// It's safe for concurrent use, only one request will be made while the others wait.
 func (c *Client) Token() (*oauth2.Token, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.token == nil || time.Now().UTC().After(c.token.Expiry) {
-		if _, err := c.RequestAccessToken(); err != nil {
-			return nil, err
+	return c.tokenContext(context.Background())
+}
+
+// tokenContext is Token bounded by the ctx, it's used by every request of the client
+func (c *Client) tokenContext(ctx context.Context) (*oauth2.Token, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	now := time.Now().UTC()
+	if c.token != nil && now.Add(tokenExpiryDelta).Before(c.token.Expiry) {
+		return c.token, nil
+	}
+
+	return c.renewToken(ctx, now)
+}
+
+// renewToken requests the new token and caches it, the caller must hold `tokenMu`.
+// The cached token is only replaced if the request succeeds.
+func (c *Client) renewToken(ctx context.Context, now time.Time) (*oauth2.Token, error) {
+	// try to use the refresh token first, fallback to client credentials if it fails
+	// the expiry of the refresh token is unknown if the token is given by Config.Token
+	if c.token != nil && c.token.RefreshToken != "" &&
+		(c.refreshExpiry.IsZero() || now.Before(c.refreshExpiry)) {
+		src := GetAccessTokenRequest{}
+		src.GrantType = grantTypeRefreshToken
+		src.RefreshToken = c.token.RefreshToken
+		if _, err := c.fetchToken(ctx, src); err == nil {
+			return c.token, nil
 		}
 	}
+
+	src := GetAccessTokenRequest{}
+	src.GrantType = grantTypeClientCredentials
+	if _, err := c.fetchToken(ctx, src); err != nil {
+		return nil, err
+	}
 	return c.token, nil
 }
 
-// RequestAccessToken :
+// NewTokenSource : returns the token source which caches the token of the credentials
+// in cfg, only ClientID, ClientSecret, Sandbox, OAuthEndpoint, HTTPClient, UserAgent,
+// Token and TokenPersist are used. It's safe for concurrent use, so it can be shared
+// by the clients of the same credentials via Config.TokenSource, e.g. one client per store.
+func NewTokenSource(cfg Config) oauth2.TokenSource {
+	return newTokenClient(cfg)
+}
+
+// RequestAccessToken : request a new client credentials token and cache it
 func (c *Client) RequestAccessToken() (*GetAccessTokenResponse, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	src := GetAccessTokenRequest{}
+	src.GrantType = grantTypeClientCredentials
+	return c.fetchToken(context.Background(), src)
+}
+
+// RefreshAccessToken : refresh the access token and cache it
+func (c *Client) RefreshAccessToken(refreshToken string) (*GetAccessTokenResponse, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
 	src := GetAccessTokenRequest{}
-	src.GrantType = "client_credentials"
+	src.GrantType = grantTypeRefreshToken
+	src.RefreshToken = refreshToken
+	return c.fetchToken(context.Background(), src)
+}
+
+// fetchToken requests the token and caches it, the caller must hold `tokenMu`
+func (c *Client) fetchToken(ctx context.Context, src GetAccessTokenRequest) (*GetAccessTokenResponse, error) {
+	dest, err := c.requestToken(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	c.token = dest.oauth2Token(now)
+	c.refreshExpiry = now.Add(time.Duration(dest.RefreshTokenExpiresIn) * time.Second)
+	if c.tokenPersist != nil {
+		c.tokenPersist(c.token)
+	}
+	return dest, nil
+}
+
+// ForceTokenRefresh : request a new token even the cached one isn't expired, the refresh
+// token is used first like Token. The cached token is kept if the request fails.
+// It only affects the default token source of the client.
+func (c *Client) ForceTokenRefresh(ctx context.Context) (*oauth2.Token, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.renewToken(ctx, time.Now().UTC())
+}
+
+// ClientCredentialsToken : request a token using the `client_credentials` grant,
+// which is for server-to-server integration. The token is scoped to the merchant
+// owning the client id, so the store must belong to that merchant (see Config.StoreID).
+// The returned token doesn't replace the token cached by the client.
+func (c *Client) ClientCredentialsToken(ctx context.Context) (*oauth2.Token, error) {
+	src := GetAccessTokenRequest{}
+	src.GrantType = grantTypeClientCredentials
+	dest, err := c.requestToken(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	return dest.oauth2Token(time.Now().UTC()), nil
+}
+
+// AuthCodeURL : returns the url of RM's consent page for the `authorization_code` flow,
+// the merchant will be redirected to redirectURI with the code and state after granting
+// the consent, then the code can be exchanged using ExchangeCode.
+func (c *Client) AuthCodeURL(state, redirectURI string, scopes []string) string {
+	params := url.Values{}
+	params.Set("client_id", c.clientID)
+	params.Set("response_type", "code")
+	params.Set("redirect_uri", redirectURI)
+	if len(scopes) > 0 {
+		params.Set("scope", strings.Join(scopes, " "))
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return c.oauthURL("/v1/auth/authorize?" + params.Encode())
+}
+
+// ExchangeCode : exchange the code returned by the consent page using the
+// `authorization_code` grant, which is for user-facing integration. The token is
+// scoped to the merchant who granted the consent, so the store id must be one of
+// that merchant's stores instead of Config.StoreID. The redirect uri must be the
+// same as the one used to request the code.
+// The returned token doesn't replace the token cached by the client.
+func (c *Client) ExchangeCode(ctx context.Context, code, redirectURI string) (*oauth2.Token, error) {
+	src := GetAccessTokenRequest{}
+	src.GrantType = grantTypeAuthorizationCode
+	src.Code = code
+	src.RedirectURI = redirectURI
+	dest, err := c.requestToken(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	return dest.oauth2Token(time.Now().UTC()), nil
+}
+
+// oauth2Token converts the response to the token, the scopes are exposed as the
+// space-delimited string of Extra("scope")
+func (r GetAccessTokenResponse) oauth2Token(now time.Time) *oauth2.Token {
+	tkn := &oauth2.Token{
+		AccessToken:  r.AccessToken,
+		TokenType:    r.TokenType,
+		RefreshToken: r.RefreshToken,
+		Expiry:       now.Add(time.Duration(r.ExpiresIn) * time.Second),
+	}
+	return tkn.WithExtra(map[string]interface{}{
+		"scope": strings.Join(r.Scope, " "),
+	})
+}
+
+// TokenScopes : returns the scopes granted to the token of the client, e.g. to assert
+// the credential is provisioned with the payment scope. It's empty if the token
+// source doesn't expose the scopes, e.g. oauth2.StaticTokenSource.
+func (c *Client) TokenScopes(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tkn, err := tokenOf(ctx, c.tokenSource())
+	if err != nil {
+		return nil, err
+	}
+	scope, _ := tkn.Extra("scope").(string)
+	return strings.Fields(scope), nil
+}
+
+// requestToken is deliberately separated from `do`, the oauth endpoint authenticates
+// the client by the basic auth of client id and secret, so the request isn't signed.
+// Unlike the RFC 6749 form body, RM's `/v1/token` expects the grant in JSON.
+func (c *Client) requestToken(ctx context.Context, src GetAccessTokenRequest) (*GetAccessTokenResponse, error) {
 	b, err := json.Marshal(src)
 	if err != nil {
 		return nil, err
 	}
 
-	reqUrl, _ := url.Parse(c.oauthEndpoint + "/v1/token")
+	respBytes, err := c.postOAuth(ctx, "/v1/token", "application/json", b)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := GetAccessTokenResponse{}
+	if err := json.Unmarshal(respBytes, &dest); err != nil {
+		return nil, err
+	}
+	return &dest, nil
+}
+
+// RevokeToken : revoke the access token or refresh token, e.g. when the merchant
+// disconnects the app of `authorization_code` flow. The cached token of the client
+// is discarded as well if it's revoked. Like `/v1/token`, the token is sent in JSON
+// instead of the RFC 7009 form body.
+func (c *Client) RevokeToken(ctx context.Context, token string) error {
+	b, err := json.Marshal(struct {
+		Token string `json:"token"`
+	}{token})
+	if err != nil {
+		return err
+	}
+	if _, err := c.postOAuth(ctx, "/v1/token/revoke", "application/json", b); err != nil {
+		return err
+	}
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.token != nil && (c.token.AccessToken == token || c.token.RefreshToken == token) {
+		c.token = nil
+	}
+	return nil
+}
+
+// postOAuth sends the request to the oauth endpoint with the basic auth of the client
+func (c *Client) postOAuth(ctx context.Context, path, contentType string, b []byte) ([]byte, error) {
+	reqUrl, _ := url.Parse(c.oauthURL(path))
 	req := new(http.Request)
 	req.Method = "POST"
 	req.URL = reqUrl
 	req.Body = ioutil.NopCloser(bytes.NewBuffer(b))
 	req.Header = http.Header{
-		"Content-Type":  {"application/json"},
+		"Content-Type":  {contentType},
+		"User-Agent":    {c.userAgent},
 		"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(c.clientID+":"+c.clientSecret))},
 	}
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
-	respBytes, err := ioutil.ReadAll(res.Body)
+	respBytes, err := readBody(res)
 	if err != nil {
 		return nil, err
 	}
 
-	dest := GetAccessTokenResponse{}
-	if err := json.Unmarshal(respBytes, &dest); err != nil {
-		return nil, err
-	}
-
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		return nil, newError(reqUrl.String(), b, respBytes)
+		rmErr := newError(res.StatusCode, reqUrl.String(), b, respBytes)
+		rmErr.RequestID = requestID(res.Header)
+		return nil, rmErr
 	}
-
-	c.token = &oauth2.Token{
-		AccessToken:  dest.AccessToken,
-		TokenType:    dest.TokenType,
-		RefreshToken: dest.RefreshToken,
-		// allow token to expires earlier 30min to prevent token expires issue
-		Expiry: time.Now().UTC().
-			Add(-30 * time.Minute).
-			Add(time.Duration(dest.ExpiresIn) * time.Second),
-	}
-	return &dest, nil
+	return respBytes, nil
 }
@@ -0,0 +1,469 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestToken(t *testing.T) {
+	var (
+		grants  []string
+		counter int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := GetAccessTokenRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		grants = append(grants, req.GrantType)
+		atomic.AddInt32(&counter, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(GetAccessTokenResponse{
+			AccessToken:           "access-token",
+			TokenType:             "Bearer",
+			ExpiresIn:             3600,
+			RefreshToken:          "refresh-token",
+			RefreshTokenExpiresIn: 7200,
+		})
+	}))
+	defer srv.Close()
+
+	client := mockRmClient()
+	client.oauthEndpoint = srv.URL
+
+	tkn, err := client.Token()
+	require.NoError(t, err)
+	require.Equal(t, "access-token", tkn.AccessToken)
+	require.Equal(t, "refresh-token", tkn.RefreshToken)
+
+	// cached token should be reused
+	_, err = client.Token()
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&counter))
+
+	// token which is about to expire should be refreshed
+	client.token.Expiry = client.token.Expiry.Add(-3590 * time.Second)
+	_, err = client.Token()
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&counter))
+	require.Equal(t, []string{"client_credentials", "refresh_token"}, grants)
+
+	// force refresh should always request a new token
+	_, err = client.ForceTokenRefresh(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&counter))
+}
+
+func TestForceTokenRefresh(t *testing.T) {
+	var (
+		grants []string
+		fail   bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := GetAccessTokenRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		grants = append(grants, req.GrantType)
+
+		w.Header().Set("Content-Type", "application/json")
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(GetAccessTokenResponse{
+			AccessToken:  "new-access-token",
+			TokenType:    "Bearer",
+			ExpiresIn:    3600,
+			RefreshToken: "new-refresh-token",
+		})
+	}))
+	defer srv.Close()
+
+	client := mockRmClient()
+	client.oauthEndpoint = srv.URL
+	// e.g. the token of Config.Token
+	token := &oauth2.Token{AccessToken: "access-token", RefreshToken: "refresh-token", Expiry: time.Now().Add(time.Hour)}
+	client.token = token
+
+	// the cached token is kept if the refresh fails
+	fail = true
+	_, err := client.ForceTokenRefresh(context.Background())
+	require.Error(t, err)
+	require.Equal(t, token, client.token)
+	require.Equal(t, []string{"refresh_token", "client_credentials"}, grants)
+
+	grants = nil
+	fail = false
+	tkn, err := client.ForceTokenRefresh(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "new-access-token", tkn.AccessToken)
+	require.Equal(t, "new-refresh-token", client.token.RefreshToken)
+	require.Equal(t, []string{"refresh_token"}, grants)
+}
+
+func TestTokenPersist(t *testing.T) {
+	var grants []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := GetAccessTokenRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		grants = append(grants, req.GrantType)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(GetAccessTokenResponse{
+			AccessToken:           "new-access-token",
+			ExpiresIn:             3600,
+			RefreshToken:          "new-refresh-token",
+			RefreshTokenExpiresIn: 7200,
+		})
+	}))
+	defer srv.Close()
+
+	var persisted []*oauth2.Token
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	client := NewClient(Config{
+		ClientID:      "xxx",
+		ClientSecret:  "xxx",
+		PrivateKey:    pk,
+		OAuthEndpoint: srv.URL,
+		Token: &oauth2.Token{
+			AccessToken:  "shared-access-token",
+			RefreshToken: "shared-refresh-token",
+			Expiry:       time.Now().Add(time.Hour),
+		},
+		TokenPersist: func(tkn *oauth2.Token) {
+			persisted = append(persisted, tkn)
+		},
+	})
+
+	// the initial token is used without requesting a new one
+	tkn, err := client.Token()
+	require.NoError(t, err)
+	require.Equal(t, "shared-access-token", tkn.AccessToken)
+	require.Empty(t, grants)
+	require.Empty(t, persisted)
+
+	// expired initial token is refreshed using its refresh token
+	client.token.Expiry = time.Now()
+	tkn, err = client.Token()
+	require.NoError(t, err)
+	require.Equal(t, "new-access-token", tkn.AccessToken)
+	require.Equal(t, []string{"refresh_token"}, grants)
+	require.Len(t, persisted, 1)
+	require.Equal(t, tkn, persisted[0])
+}
+
+func TestSharedTokenSource(t *testing.T) {
+	var counter int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/token" {
+			atomic.AddInt32(&counter, 1)
+			w.Write([]byte(`{"accessToken":"shared-token","tokenType":"Bearer","expiresIn":3600}`))
+			return
+		}
+		require.Equal(t, "Bearer shared-token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	src := NewTokenSource(Config{
+		ClientID:      "xxx",
+		ClientSecret:  "xxx",
+		OAuthEndpoint: srv.URL,
+	})
+
+	var wg sync.WaitGroup
+	for _, storeID := range []string{"1", "2", "3"} {
+		client := NewClient(Config{
+			ClientID:     "xxx",
+			PrivateKey:   pk,
+			StoreID:      storeID,
+			TokenSource:  src,
+			OpenEndpoint: srv.URL,
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetStores(context.Background())
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&counter))
+}
+
+func TestRefreshOnUnauthorized(t *testing.T) {
+	var (
+		tokens int32
+		calls  int32
+		revoke int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/token" {
+			n := atomic.AddInt32(&tokens, 1)
+			json.NewEncoder(w).Encode(GetAccessTokenResponse{
+				AccessToken: "token-" + strconv.Itoa(int(n)),
+				ExpiresIn:   3600,
+			})
+			return
+		}
+
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&revoke) == 1 || r.Header.Get("Authorization") == "Bearer revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":"UNAUTHORIZED"}}`))
+			return
+		}
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	client := NewClient(Config{
+		ClientID:      "xxx",
+		ClientSecret:  "xxx",
+		PrivateKey:    pk,
+		OAuthEndpoint: srv.URL,
+		OpenEndpoint:  srv.URL,
+		Token:         &oauth2.Token{AccessToken: "revoked", Expiry: time.Now().Add(time.Hour)},
+	})
+
+	_, err := client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&tokens))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	require.Equal(t, "token-1", client.token.AccessToken)
+
+	// it only retries once
+	atomic.StoreInt32(&revoke, 1)
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.GetStores(context.Background())
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.Equal(t, int32(2), atomic.LoadInt32(&tokens))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	client := mockRmClient()
+	u, err := url.Parse(client.AuthCodeURL("state", "https://www.google.com", []string{"manage_store", "manage_payment"}))
+	require.NoError(t, err)
+	require.Equal(t, "sb-oauth.revenuemonster.my", u.Host)
+	require.Equal(t, "/v1/auth/authorize", u.Path)
+
+	q := u.Query()
+	require.Equal(t, client.clientID, q.Get("client_id"))
+	require.Equal(t, "code", q.Get("response_type"))
+	require.Equal(t, "manage_store manage_payment", q.Get("scope"))
+	require.Equal(t, "state", q.Get("state"))
+	require.Equal(t, "https://www.google.com", q.Get("redirect_uri"))
+}
+
+func TestExchangeCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := GetAccessTokenRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "/v1/token", r.URL.Path)
+
+		resp := GetAccessTokenResponse{TokenType: "Bearer", ExpiresIn: 3600}
+		switch req.GrantType {
+		case grantTypeAuthorizationCode:
+			require.Equal(t, "code", req.Code)
+			require.Equal(t, "https://www.google.com", req.RedirectURI)
+			resp.AccessToken = "user-token"
+		case grantTypeClientCredentials:
+			resp.AccessToken = "client-token"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockRmClient()
+	)
+	client.oauthEndpoint = srv.URL
+
+	tkn, err := client.ExchangeCode(ctx, "code", "https://www.google.com")
+	require.NoError(t, err)
+	require.Equal(t, "user-token", tkn.AccessToken)
+	require.True(t, tkn.Valid())
+
+	tkn, err = client.ClientCredentialsToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "client-token", tkn.AccessToken)
+
+	// explicit flows shouldn't replace the cached token
+	require.Nil(t, client.token)
+}
+
+func TestTokenRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		clientID, clientSecret, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "1599646279297591629", clientID)
+		require.Equal(t, "NekiDbnNHbHLWdRmbqtwBCqywfYkVVnE", clientSecret)
+
+		// the token request is authenticated by the secret instead of signature
+		require.Empty(t, r.Header.Get("X-Signature"))
+		require.Empty(t, r.Header.Get("X-Nonce-Str"))
+		require.Empty(t, r.Header.Get("X-Timestamp"))
+
+		b, _ := ioutil.ReadAll(r.Body)
+		require.JSONEq(t, `{"grantType":"client_credentials"}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"accessToken":"access-token","tokenType":"Bearer","expiresIn":3600}`))
+	}))
+	defer srv.Close()
+
+	client := mockRmClient()
+	client.oauthEndpoint = srv.URL
+	tkn, err := client.Token()
+	require.NoError(t, err)
+	require.Equal(t, "access-token", tkn.AccessToken)
+}
+
+func TestTokenConcurrency(t *testing.T) {
+	var counter int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&counter, 1)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(GetAccessTokenResponse{
+			AccessToken: "access-token",
+			TokenType:   "Bearer",
+			ExpiresIn:   3600,
+		})
+	}))
+	defer srv.Close()
+
+	client := mockRmClient()
+	client.oauthEndpoint = srv.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tkn, err := client.Token()
+			require.NoError(t, err)
+			require.Equal(t, "access-token", tkn.AccessToken)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&counter))
+}
+
+type blockingTokenSource chan struct{}
+
+func (s blockingTokenSource) Token() (*oauth2.Token, error) {
+	<-s
+	return &oauth2.Token{AccessToken: "access-token"}, nil
+}
+
+func TestTokenContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the oauth server hangs
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := mockRmClient()
+	client.oauthEndpoint = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetStore(ctx, "1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// the token source which doesn't take the ctx is bounded as well
+	client.SetTokenSource(blockingTokenSource(release))
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = client.GetStore(ctx, "1")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestRevokeToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/token/revoke" ||
+			r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, _, ok := r.BasicAuth(); !ok {
+			t.Errorf("missing basic auth")
+		}
+
+		var body struct {
+			Token string `json:"token"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		if body.Token != "access-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"INVALID_TOKEN","message":"invalid token"}}`))
+			return
+		}
+		w.Write([]byte(`{"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockRmClient()
+	client.oauthEndpoint = srv.URL
+	client.token = &oauth2.Token{AccessToken: "access-token", Expiry: time.Now().Add(time.Hour)}
+
+	require.Error(t, client.RevokeToken(context.Background(), "unknown"))
+	require.NotNil(t, client.token)
+	require.NoError(t, client.RevokeToken(context.Background(), "access-token"))
+	require.Nil(t, client.token)
+}
+
+func TestTokenScopes(t *testing.T) {
+	for _, scope := range []string{`"payment loyalty:read"`, `["payment","loyalty:read"]`} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"accessToken":"access-token","tokenType":"Bearer","expiresIn":3600,"scope":` + scope + `}`))
+		}))
+
+		client := mockRmClient()
+		client.oauthEndpoint = srv.URL
+		scopes, err := client.TokenScopes(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"payment", "loyalty:read"}, scopes)
+
+		tkn, err := client.Token()
+		require.NoError(t, err)
+		require.Equal(t, "payment loyalty:read", tkn.Extra("scope"))
+		srv.Close()
+	}
+
+	// the scopes are unknown
+	client := mockRmClient()
+	client.SetTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}))
+	scopes, err := client.TokenScopes(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, scopes)
+}
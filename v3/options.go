@@ -0,0 +1,117 @@
+package rm
+
+import "context"
+
+// RequestOption : the option of a single request
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	storeID        string
+	idempotencyKey string
+	// idempotent reports whether the request supports idempotency key
+	idempotent  bool
+	rawResponse *[]byte
+	contentType string
+	dryRun      bool
+	signature   *RequestSignature
+	operation   string
+}
+
+// WithStoreID : override the store id of the client for the request
+func WithStoreID(storeID string) RequestOption {
+	return func(o *requestOptions) {
+		o.storeID = storeID
+	}
+}
+
+// WithIdempotencyKey : set the idempotency key of the payment creation, so a retried
+// request returns the original payment instead of creating a new one.
+// The key is generated automatically if retry is enabled.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithRawResponse : captures the verbatim response body of the successful request into dst,
+// e.g. to archive the original response for audit
+func WithRawResponse(dst *[]byte) RequestOption {
+	return func(o *requestOptions) {
+		o.rawResponse = dst
+	}
+}
+
+// WithContentType : overrides the content type of the request, default to "application/json".
+// The body other than json must be []byte or io.Reader, and it's sent and signed as it is.
+func WithContentType(contentType string) RequestOption {
+	return func(o *requestOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithDryRun : builds and signs the request without sending it, see Config.DryRun
+func WithDryRun() RequestOption {
+	return func(o *requestOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithRequestSignature : captures the nonce, timestamp and signature of the request into dst,
+// e.g. to correlate the steps of a multi-step flow. They're of the last attempt if it's
+// retried, and they're captured even if the request fails.
+func WithRequestSignature(dst *RequestSignature) RequestOption {
+	return func(o *requestOptions) {
+		o.signature = dst
+	}
+}
+
+// WithOperationName : overrides the operation name of the request, which is the name of
+// the span, the logs and the metrics, e.g. "create_payment_checkout_fpx".
+// Keep the cardinality low, e.g. don't include the order id.
+func WithOperationName(name string) RequestOption {
+	return func(o *requestOptions) {
+		o.operation = name
+	}
+}
+
+type operationNameKey struct{}
+
+// ContextWithOperationName : decorates the operation name of the requests made with the context,
+// it's applied after WithOperationName
+func ContextWithOperationName(ctx context.Context, decorate func(operation string) string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, decorate)
+}
+
+// operationNameOf returns the operation name of the request, fallback to the name of the method
+func (o *requestOptions) operationNameOf(ctx context.Context, name string) string {
+	if o.operation != "" {
+		name = o.operation
+	}
+	if decorate, ok := ctx.Value(operationNameKey{}).(func(string) string); ok && decorate != nil {
+		name = decorate(name)
+	}
+	return name
+}
+
+// idempotent marks the request as supporting idempotency key
+func idempotent() RequestOption {
+	return func(o *requestOptions) {
+		o.idempotent = true
+	}
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := new(requestOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// storeIDOf returns the store id of the request options, fallback to the store id of the client
+func (c *Client) storeIDOf(o *requestOptions) string {
+	if o.storeID != "" {
+		return o.storeID
+	}
+	return c.storeID
+}
@@ -0,0 +1,89 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// defaultPageSize is the number of items requested per page when the limit isn't specified
+const defaultPageSize = 100
+
+// Pagination : the page of the list, it can be handed over as the cursor to resume the paging
+type Pagination struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Total  int `json:"total"`
+}
+
+// Next : returns the next page and reports whether it exists, it's always false
+// if the total is unknown
+//
+//	for p, ok := page, true; ok; p, ok = p.Next() {
+//		stores, _, err := client.ListStores(ctx, rm.ListStoreOptions{Offset: p.Offset, Limit: p.Limit})
+//	}
+func (p Pagination) Next() (Pagination, bool) {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	next := Pagination{Offset: p.Offset + limit, Limit: limit, Total: p.Total}
+	return next, next.Offset < p.Total
+}
+
+// iterate walks through the pages of the list endpoint until it's exhausted,
+// fn will be called for every item. Returning an error from fn stops the iteration.
+func (c *Client) iterate(
+	ctx context.Context,
+	operationName string,
+	endpoint string,
+	params url.Values,
+	fn func(json.RawMessage) error,
+	opts ...RequestOption,
+) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		params.Set("offset", strconv.Itoa(offset))
+		params.Set("limit", strconv.Itoa(limit))
+
+		items := make([]json.RawMessage, 0)
+		env, err := c.doUnwrap(
+			ctx,
+			operationName,
+			"get",
+			endpoint+"?"+params.Encode(),
+			nil,
+			&items,
+			opts...,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+
+		offset += len(items)
+		if len(items) < limit ||
+			(env.Pagination.Total > 0 && offset >= env.Pagination.Total) {
+			return nil
+		}
+	}
+}
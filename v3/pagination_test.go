@@ -0,0 +1,26 @@
+package rm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginationNext(t *testing.T) {
+	var (
+		p       = Pagination{Offset: 0, Limit: 2, Total: 5}
+		offsets []int
+	)
+	for ok := true; ok; p, ok = p.Next() {
+		offsets = append(offsets, p.Offset)
+	}
+	require.Equal(t, []int{0, 2, 4}, offsets)
+
+	next, ok := Pagination{Offset: 0, Limit: 0, Total: 150}.Next()
+	require.True(t, ok)
+	require.Equal(t, Pagination{Offset: defaultPageSize, Limit: defaultPageSize, Total: 150}, next)
+
+	// total is unknown
+	_, ok = Pagination{Offset: 0, Limit: 10}.Next()
+	require.False(t, ok)
+}
@@ -0,0 +1,48 @@
+package rm
+
+import (
+	"context"
+	"errors"
+)
+
+// CaptureRequest :
+type CaptureRequest struct {
+	TransactionID string `json:"transactionId"`
+	Amount        Amount `json:"amount"`
+}
+
+// CaptureResponse :
+type CaptureResponse struct {
+	Item Transaction `json:"item"`
+	Code string      `json:"code"`
+}
+
+// CapturePayment : capture the pre-authorized payment, the amount may be lower than
+// the authorized amount. It returns ErrAlreadyCaptured if the payment is captured,
+// and ErrCaptureExceedsAuth if the amount exceeds the authorized amount.
+func (c *Client) CapturePayment(
+	ctx context.Context,
+	req CaptureRequest,
+	opts ...RequestOption,
+) (*CaptureResponse, error) {
+	if req.TransactionID == "" {
+		return nil, errors.New("rm: missing transaction id of the pre-authorized payment")
+	}
+	if req.Amount <= 0 {
+		return nil, errors.New("rm: capture amount must be positive")
+	}
+
+	resp := new(CaptureResponse)
+	if err := c.do(
+		ctx,
+		"capture_payment",
+		"post",
+		c.openURL("/v3/payment/capture"),
+		req,
+		resp,
+		append(opts, idempotent())...,
+	); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,50 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapturePayment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := CaptureRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "/v3/payment/capture", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.TransactionID == "captured":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"PAYMENT_ALREADY_CAPTURED","message":"Payment already captured"}}`))
+		case req.Amount > 5000:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"CAPTURE_AMOUNT_EXCEED_AUTHORIZED_AMOUNT","message":"Capture amount exceeds the authorized amount"}}`))
+		default:
+			w.Write([]byte(`{"item":{"transactionId":"` + req.TransactionID + `","status":"SUCCESS"},"code":"SUCCESS"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+
+	resp, err := client.CapturePayment(ctx, CaptureRequest{TransactionID: "200910090708300425661809", Amount: 3000})
+	require.NoError(t, err)
+	require.Equal(t, "200910090708300425661809", resp.Item.TransactionID)
+
+	_, err = client.CapturePayment(ctx, CaptureRequest{TransactionID: "captured", Amount: 3000})
+	require.ErrorIs(t, err, ErrAlreadyCaptured)
+
+	_, err = client.CapturePayment(ctx, CaptureRequest{TransactionID: "200910090708300425661809", Amount: 6000})
+	require.ErrorIs(t, err, ErrCaptureExceedsAuth)
+
+	_, err = client.CapturePayment(ctx, CaptureRequest{TransactionID: "200910090708300425661809"})
+	require.Error(t, err)
+}
@@ -8,27 +8,31 @@ import (
 // CreatePaymentCheckoutRequest :
 type CreatePaymentCheckoutRequest struct {
 	Order struct {
-		ID             string `json:"id"`
-		Title          string `json:"title"`
-		Detail         string `json:"detail"`
-		AdditionalData string `json:"additionalData"`
-		Amount         uint   `json:"amount"`
-		Currency       string `json:"currencyType"`
+		ID             string       `json:"id"`
+		Title          string       `json:"title"`
+		Detail         string       `json:"detail,omitempty"`
+		AdditionalData string       `json:"additionalData,omitempty"`
+		Amount         Amount       `json:"amount"`
+		Currency       CurrencyType `json:"currencyType"`
 	} `json:"order"`
-	Customer struct {
-		UserID      string `json:"userId"`
-		Email       string `json:"email,omitempty"`
-		CountryCode string `json:"countryCode,omitempty"`
-		PhoneNumber string `json:"phoneNumber,omitempty"`
-	} `json:"customer"`
-	Type             PaymentType     `json:"type"`
-	Method           []PaymentMethod `json:"method"`
-	ExcludeMethod    []string        `json:"excludeMethod"`
-	StoreID          string          `json:"storeId"`
-	RedirectURL      string          `json:"redirectUrl"`
-	NotifyURL        string          `json:"notifyUrl"`
-	LayoutVersion    layout          `json:"layoutVersion"`
-	ExpiresInSeconds int64           `json:"expiresInSeconds"`
+	Customer      *CheckoutCustomer `json:"customer,omitempty"`
+	Type          PaymentType       `json:"type"`
+	Method        []PaymentMethod   `json:"method"`
+	ExcludeMethod []PaymentMethod   `json:"excludeMethod,omitempty"`
+	StoreID       string            `json:"storeId,omitempty"`
+	// RedirectURL is required by RM, the customer is redirected to it after the payment
+	RedirectURL      string `json:"redirectUrl"`
+	NotifyURL        string `json:"notifyUrl,omitempty"`
+	LayoutVersion    layout `json:"layoutVersion"`
+	ExpiresInSeconds int64  `json:"expiresInSeconds,omitempty"`
+}
+
+// CheckoutCustomer : the customer of the checkout, it's omitted if it's nil
+type CheckoutCustomer struct {
+	UserID      string `json:"userId,omitempty"`
+	Email       string `json:"email,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	PhoneNumber string `json:"phoneNumber,omitempty"`
 }
 
 // CreatePaymentCheckoutResponse :
@@ -36,15 +40,23 @@ type CreatePaymentCheckoutResponse struct {
 	Item struct {
 		CheckoutID string `json:"checkoutId"`
 		URL        string `json:"url"`
+		DeepLink   string `json:"deeplink"`
 	} `json:"item"`
 	Code string `json:"code"`
 }
 
+// Links : returns the validated links of the checkout
+func (r CreatePaymentCheckoutResponse) Links() (PaymentLinks, error) {
+	return newPaymentLinks(r.Item.URL, "", r.Item.DeepLink)
+}
+
 // CreatePaymentCheckout :
 func (c *Client) CreatePaymentCheckout(
 	ctx context.Context,
 	req CreatePaymentCheckoutRequest,
+	opts ...RequestOption,
 ) (*CreatePaymentCheckoutResponse, error) {
+	o := newRequestOptions(opts)
 	req.LayoutVersion = LayoutV3
 	if req.Method == nil {
 		req.Method = make([]PaymentMethod, 0)
@@ -53,15 +65,15 @@ func (c *Client) CreatePaymentCheckout(
 		req.Type = PaymentTypeWeb
 	}
 	if req.Order.Currency == "" {
-		req.Order.Currency = "MYR"
+		req.Order.Currency = CurrencyMYR
 	}
 	if req.StoreID == "" {
-		if c.storeID != "" {
-			req.StoreID = c.storeID
+		if storeID := c.storeIDOf(o); storeID != "" {
+			req.StoreID = storeID
 		} else {
 			// prevent concurrency race
-			c.mu.Lock()
-			defer c.mu.Lock()
+			c.storeMu.Lock()
+			defer c.storeMu.Unlock()
 			res, err := c.GetStores(ctx)
 			if err != nil {
 				return nil, err
@@ -80,11 +92,28 @@ func (c *Client) CreatePaymentCheckout(
 		ctx,
 		"create_payment_checkout",
 		"post",
-		c.openEndpoint+"/v3/payment/online",
+		c.openURL("/v3/payment/online"),
 		req,
 		resp,
+		append(opts, idempotent())...,
 	); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
+
+// OnlinePaymentRequest : alias of CreatePaymentCheckoutRequest
+type OnlinePaymentRequest = CreatePaymentCheckoutRequest
+
+// OnlinePaymentResponse : alias of CreatePaymentCheckoutResponse
+type OnlinePaymentResponse = CreatePaymentCheckoutResponse
+
+// CreateOnlinePayment : create a web checkout, the redirect url is returned in `Item.URL`.
+// This is the same as CreatePaymentCheckout.
+func (c *Client) CreateOnlinePayment(
+	ctx context.Context,
+	req OnlinePaymentRequest,
+	opts ...RequestOption,
+) (*OnlinePaymentResponse, error) {
+	return c.CreatePaymentCheckout(ctx, req, opts...)
+}
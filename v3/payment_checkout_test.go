@@ -0,0 +1,102 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOnlinePayment(t *testing.T) {
+	var last OnlinePaymentRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v3/payment/online", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&last))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"checkoutId":"1234","url":"https://sb-pg.revenuemonster.my/checkout?checkoutId=1234"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	req := OnlinePaymentRequest{}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	req.Order.Amount = 1000
+	req.RedirectURL = "https://www.google.com"
+	req.NotifyURL = "https://www.google.com"
+	resp, err := client.CreateOnlinePayment(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, "1234", resp.Item.CheckoutID)
+	require.NotEmpty(t, resp.Item.URL)
+
+	require.Equal(t, client.storeID, last.StoreID)
+	require.Equal(t, PaymentTypeWeb, last.Type)
+	require.Equal(t, CurrencyMYR, last.Order.Currency)
+	require.Equal(t, LayoutV3, last.LayoutVersion)
+
+	// override the store id of the client
+	_, err = client.CreateOnlinePayment(context.Background(), req, WithStoreID("1234"))
+	require.NoError(t, err)
+	require.Equal(t, "1234", last.StoreID)
+}
+
+func TestDuplicateOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":"DUPLICATE_ORDER_ID","message":"Order id already exists","transactionId":"200910090708300425661809"}}`))
+	}))
+	defer srv.Close()
+
+	req := OnlinePaymentRequest{}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	req.Order.Amount = 1000
+	req.RedirectURL = "https://www.google.com"
+	req.NotifyURL = "https://www.google.com"
+	_, err := mockServerClient(srv).CreateOnlinePayment(context.Background(), req)
+	require.True(t, errors.Is(err, ErrDuplicateOrder))
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, "200910090708300425661809", apiErr.TransactionID)
+}
+
+func TestCreatePaymentCheckoutOmitEmpty(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"checkoutId":"1234"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+
+	req := CreatePaymentCheckoutRequest{}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	req.RedirectURL = "https://www.google.com"
+	_, err := client.CreatePaymentCheckout(context.Background(), req)
+	require.NoError(t, err)
+
+	// the unset optional fields are omitted, while the unset method is sent as all the methods
+	for _, k := range []string{"customer", "excludeMethod", "notifyUrl", "expiresInSeconds"} {
+		require.NotContains(t, body, k)
+	}
+	require.Equal(t, []interface{}{}, body["method"])
+	require.Equal(t, "https://www.google.com", body["redirectUrl"])
+
+	req.Customer = &CheckoutCustomer{Email: "test@example.com"}
+	req.Method = []PaymentMethod{PaymentMethodBoostMalaysia}
+	_, err = client.CreatePaymentCheckout(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"email": "test@example.com"}, body["customer"])
+	require.Equal(t, []interface{}{"BOOST_MY"}, body["method"])
+}
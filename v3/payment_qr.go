@@ -0,0 +1,217 @@
+package rm
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CreateQRRequest :
+type CreateQRRequest struct {
+	Amount       Amount          `json:"amount"`
+	CurrencyType CurrencyType    `json:"currencyType"`
+	Method       []PaymentMethod `json:"method"`
+	Order        struct {
+		ID             string `json:"id"`
+		Title          string `json:"title"`
+		Detail         string `json:"detail,omitempty"`
+		AdditionalData string `json:"additionalData,omitempty"`
+	} `json:"order"`
+	StoreID   string     `json:"storeId,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// QRResponse :
+type QRResponse struct {
+	ID           string `json:"id"`
+	Code         string `json:"code"`
+	QrCodeURL    string `json:"qrCodeUrl"`
+	DeepLink     string `json:"deeplink"`
+	Amount       Amount `json:"amount"`
+	CurrencyType string `json:"currencyType"`
+	Status       string `json:"status"`
+	ExpiresAt    Time   `json:"expiresAt"`
+	CreatedAt    Time   `json:"createdAt"`
+	UpdatedAt    Time   `json:"updatedAt"`
+}
+
+// Links : returns the validated links of the QR
+func (r QRResponse) Links() (PaymentLinks, error) {
+	return newPaymentLinks("", r.QrCodeURL, r.DeepLink)
+}
+
+// CreateDynamicQR :
+func (c *Client) CreateDynamicQR(
+	ctx context.Context,
+	req CreateQRRequest,
+	opts ...RequestOption,
+) (*QRResponse, error) {
+	o := newRequestOptions(opts)
+	if req.Method == nil {
+		req.Method = make([]PaymentMethod, 0)
+	}
+	if req.CurrencyType == "" {
+		req.CurrencyType = CurrencyMYR
+	}
+	if req.StoreID == "" {
+		req.StoreID = c.storeIDOf(o)
+	}
+
+	item := new(QRResponse)
+	if _, err := c.doUnwrap(
+		ctx,
+		"create_dynamic_qrcode",
+		"post",
+		c.openURL("/v3/payment/qrcode"),
+		req,
+		item,
+		append(opts, idempotent())...,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// StaticQRRequest :
+type StaticQRRequest struct {
+	StoreID string
+	// Label is shown as the order title when the customer scans the QR
+	Label  string
+	Detail string
+	// PresetAmount pre-fills the amount, leave it empty to let the customer
+	// key in the amount
+	PresetAmount   Amount
+	CurrencyType   CurrencyType
+	Method         []PaymentMethod
+	AdditionalData string
+	RedirectURL    string
+}
+
+// CreateStaticQR : creates a persistent QR which can be printed and
+// scanned repeatedly, the returned `Code` identifies the QR
+func (c *Client) CreateStaticQR(
+	ctx context.Context,
+	req StaticQRRequest,
+	opts ...RequestOption,
+) (*QRResponse, error) {
+	o := newRequestOptions(opts)
+	if req.StoreID == "" {
+		req.StoreID = c.storeIDOf(o)
+	}
+	if req.CurrencyType == "" {
+		req.CurrencyType = CurrencyMYR
+	}
+	if req.Method == nil {
+		req.Method = make([]PaymentMethod, 0)
+	}
+
+	src := struct {
+		Type            CreateTransactionQRType `json:"type"`
+		CurrencyType    CurrencyType            `json:"currencyType"`
+		Amount          Amount                  `json:"amount"`
+		IsPreFillAmount bool                    `json:"isPreFillAmount"`
+		Method          []PaymentMethod         `json:"method"`
+		Order           struct {
+			Title          string `json:"title"`
+			Detail         string `json:"detail,omitempty"`
+			AdditionalData string `json:"additionalData,omitempty"`
+		} `json:"order"`
+		Expiry struct {
+			Type string `json:"type"`
+		} `json:"expiry"`
+		RedirectURL string `json:"redirectUrl,omitempty"`
+		StoreID     string `json:"storeId,omitempty"`
+	}{
+		Type:            CreateTransactionQRTypeStatic,
+		CurrencyType:    req.CurrencyType,
+		Amount:          req.PresetAmount,
+		IsPreFillAmount: req.PresetAmount > 0,
+		Method:          req.Method,
+		RedirectURL:     req.RedirectURL,
+		StoreID:         req.StoreID,
+	}
+	src.Order.Title = req.Label
+	src.Order.Detail = req.Detail
+	src.Order.AdditionalData = req.AdditionalData
+	src.Expiry.Type = "PERMANENT"
+
+	item := new(QRResponse)
+	if _, err := c.doUnwrap(
+		ctx,
+		"create_static_qrcode",
+		"post",
+		c.openURL("/v3/payment/transaction/qrcode"),
+		src,
+		item,
+		append(opts, idempotent())...,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// QRDetail :
+type QRDetail struct {
+	Code            string                  `json:"code"`
+	Type            CreateTransactionQRType `json:"type"`
+	IsPreFillAmount bool                    `json:"isPreFillAmount"`
+	Amount          Amount                  `json:"amount"`
+	CurrencyType    CurrencyType            `json:"currencyType"`
+	QrCodeURL       string                  `json:"qrCodeUrl"`
+	Store           struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"store"`
+	Status    string `json:"status"`
+	CreatedAt Time   `json:"createdAt"`
+	UpdatedAt Time   `json:"updatedAt"`
+	// Transactions are the latest payments made against the QR
+	Transactions []Transaction `json:"-"`
+}
+
+// GetQR : returns the QR and the latest payments made against it
+func (c *Client) GetQR(ctx context.Context, qrID string) (*QRDetail, error) {
+	endpoint := c.openURL("/v3/payment/transaction/qrcode/" + url.PathEscape(qrID))
+
+	item := new(QRDetail)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_qrcode",
+		"get",
+		endpoint,
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+
+	item.Transactions = make([]Transaction, 0)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_qrcode_transactions",
+		"get",
+		endpoint+"/transactions?limit="+strconv.Itoa(defaultPageSize),
+		nil,
+		&item.Transactions,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// CancelQR : cancels the unpaid QR so it can't be paid anymore, it returns
+// ErrQRAlreadyPaid or ErrQRAlreadyCancelled if the QR isn't cancellable
+func (c *Client) CancelQR(ctx context.Context, qrID string) error {
+	if _, err := c.doUnwrap(
+		ctx,
+		"cancel_qrcode",
+		"post",
+		c.openURL("/v3/payment/transaction/qrcode/"+url.PathEscape(qrID)+"/cancel"),
+		nil,
+		nil,
+	); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateStaticQR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/payment/transaction/qrcode", r.URL.Path)
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "STATIC", body["type"])
+		require.Equal(t, "store-1", body["storeId"])
+		require.Equal(t, true, body["isPreFillAmount"])
+		require.Equal(t, float64(500), body["amount"])
+		require.Equal(t, "Table 1", body["order"].(map[string]interface{})["title"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"code":"qr-code","qrCodeUrl":"https://example.com/qr.png","amount":500,"status":"ACTIVE"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	qr, err := client.CreateStaticQR(context.Background(), StaticQRRequest{
+		StoreID:      "store-1",
+		Label:        "Table 1",
+		PresetAmount: 500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "qr-code", qr.Code)
+	require.Equal(t, "https://example.com/qr.png", qr.QrCodeURL)
+}
+
+func TestGetQR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/payment/transaction/qrcode/qr-code":
+			w.Write([]byte(`{"item":{"code":"qr-code","type":"STATIC","amount":500,"store":{"id":"store-1"},"status":"ACTIVE"},"code":"SUCCESS"}`))
+		case "/v3/payment/transaction/qrcode/qr-code/transactions":
+			w.Write([]byte(`{"items":[{"transactionId":"1","status":"SUCCESS"},{"transactionId":"2","status":"FAILED"}],"code":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	qr, err := mockServerClient(srv).GetQR(context.Background(), "qr-code")
+	require.NoError(t, err)
+	require.Equal(t, CreateTransactionQRTypeStatic, qr.Type)
+	require.Equal(t, Amount(500), qr.Amount)
+	require.Equal(t, "store-1", qr.Store.ID)
+	require.Len(t, qr.Transactions, 2)
+	require.Equal(t, PaymentStatusSuccess, qr.Transactions[0].Status)
+}
+
+func TestCancelQR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/payment/transaction/qrcode/unpaid/cancel":
+			w.Write([]byte(`{"code":"SUCCESS"}`))
+		case "/v3/payment/transaction/qrcode/paid/cancel":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"QR_CODE_ALREADY_PAID"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"QR_CODE_ALREADY_CANCELLED"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	require.NoError(t, client.CancelQR(ctx, "unpaid"))
+	require.ErrorIs(t, client.CancelQR(ctx, "paid"), ErrQRAlreadyPaid)
+	require.ErrorIs(t, client.CancelQR(ctx, "cancelled"), ErrQRAlreadyCancelled)
+}
+
+func TestCreateDynamicQR(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v3/payment/qrcode", r.URL.Path)
+		body = map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"id":"1","code":"qr-code","qrCodeUrl":"https://example.com/qr.png","amount":500,"currencyType":"MYR","status":"ACTIVE"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.storeID = "store-1"
+
+	req := CreateQRRequest{Amount: 500}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	qr, err := client.CreateDynamicQR(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &QRResponse{
+		ID:           "1",
+		Code:         "qr-code",
+		QrCodeURL:    "https://example.com/qr.png",
+		Amount:       500,
+		CurrencyType: "MYR",
+		Status:       "ACTIVE",
+	}, qr)
+
+	// the store id and currency default to the client's
+	require.Equal(t, "store-1", body["storeId"])
+	require.Equal(t, "MYR", body["currencyType"])
+	require.Equal(t, []interface{}{}, body["method"])
+
+	_, err = client.CreateDynamicQR(context.Background(), req, WithStoreID("store-2"))
+	require.NoError(t, err)
+	require.Equal(t, "store-2", body["storeId"])
+}
+
+func TestCreateDynamicQROmitEmpty(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"id":"1"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.storeID = ""
+
+	req := CreateQRRequest{Amount: 100}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	_, err := client.CreateDynamicQR(context.Background(), req)
+	require.NoError(t, err)
+
+	// RM treats the empty optional fields as invalid instead of absent
+	require.NotContains(t, body, "storeId")
+	require.NotContains(t, body, "expiresAt")
+	require.Equal(t, map[string]interface{}{"id": "1234", "title": "Testing"}, body["order"])
+}
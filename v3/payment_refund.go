@@ -2,16 +2,28 @@ package rm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
 )
 
+// RefundType :
+type RefundType string
+
+// refund types :
+const (
+	RefundTypeFull    RefundType = "FULL"
+	RefundTypePartial RefundType = "PARTIAL"
+)
+
 // RefundPaymentRequest :
 type RefundPaymentRequest struct {
 	TransactionID string `json:"transactionId"`
 	Refund        struct {
-		Type         string `json:"type"`
-		CurrencyType string `json:"currencyType"`
-		Amount       uint   `json:"amount"`
+		Type         RefundType   `json:"type"`
+		CurrencyType CurrencyType `json:"currencyType"`
+		Amount       Amount       `json:"amount"`
 	} `json:"refund"`
 	Reason string `json:"reason"`
 }
@@ -45,14 +57,14 @@ type RefundPaymentResponse struct {
 			ID     string `json:"id"`
 			Title  string `json:"title"`
 			Detail string `json:"detail"`
-			Amount int    `json:"amount"`
+			Amount Amount `json:"amount"`
 		} `json:"order"`
 		TerminalID string `json:"terminalId"`
 		Payee      struct {
 			UserID string `json:"userId"`
 		} `json:"payee"`
 		CurrencyType  string      `json:"currencyType"`
-		BalanceAmount int         `json:"balanceAmount"`
+		BalanceAmount Amount      `json:"balanceAmount"`
 		Voucher       interface{} `json:"voucher"`
 		Platform      string      `json:"platform"`
 		Method        string      `json:"method"`
@@ -71,31 +83,67 @@ type RefundPaymentResponse struct {
 	Code string `json:"code"`
 }
 
-// RefundPayment :
+// RefundPayment : it returns ErrInsufficientBalance if the settled balance of the merchant
+// isn't enough, which is retryable, unlike the other refund errors.
 func (c *Client) RefundPayment(
 	ctx context.Context,
 	req RefundPaymentRequest,
 ) (*RefundPaymentResponse, error) {
-	pymt, err := c.GetPaymentByTransactionID(ctx, req.TransactionID)
-	if err != nil {
+	// zero amount means the full refund
+	if req.Refund.Amount < 0 {
+		return nil, errors.New("rm: refund amount must be positive")
+	}
+
+	// the balance is a pointer to tell the absent field from the zero balance
+	pymt := new(struct {
+		Order struct {
+			Amount Amount `json:"amount"`
+		} `json:"order"`
+		BalanceAmount *Amount       `json:"balanceAmount"`
+		Status        PaymentStatus `json:"status"`
+	})
+	if _, err := c.doUnwrap(
+		ctx,
+		"query_payment_by_transaction_id",
+		"get",
+		c.openURL("/v3/payment/transaction/"+url.PathEscape(req.TransactionID)),
+		nil,
+		pymt,
+	); err != nil {
 		return nil, err
 	}
 
-	// if pymt.Item.Status != PaymentStatusSuccess {
-	// 	return nil,
-	// }
+	// the balance amount is the remaining amount which still can be refunded,
+	// it falls back to the order amount only if RM doesn't return it
+	balance := pymt.Order.Amount
+	if pymt.BalanceAmount != nil {
+		balance = *pymt.BalanceAmount
+	}
+	if pymt.Status == PaymentStatusFullyRefunded || balance <= 0 {
+		return nil, ErrPaymentAlreadyRefunded
+	}
 
 	// if amount is zero, we will perform full refunded
 	if req.Refund.Amount == 0 {
-		req.Refund.Type = "FULL"
-		// FIXME: support partial refund
-		// if orderResp.Item.Order.Amount < orderResp.Item.BalanceAmount {
-		// }
-		req.Refund.Amount = pymt.Item.Order.Amount
+		req.Refund.Amount = balance
+	}
+
+	if req.Refund.Amount > balance {
+		return nil, fmt.Errorf("rm: refund amount %d exceeds the refundable amount %d", req.Refund.Amount, balance)
+	}
+	if req.Refund.Type == RefundTypeFull && req.Refund.Amount != pymt.Order.Amount {
+		return nil, fmt.Errorf("rm: full refund amount %d differs from the order amount %d", req.Refund.Amount, pymt.Order.Amount)
+	}
+
+	if req.Refund.Type == "" {
+		req.Refund.Type = RefundTypePartial
+		if req.Refund.Amount == pymt.Order.Amount {
+			req.Refund.Type = RefundTypeFull
+		}
 	}
 
 	if req.Refund.CurrencyType == "" {
-		req.Refund.CurrencyType = "MYR"
+		req.Refund.CurrencyType = CurrencyMYR
 	}
 
 	resp := new(RefundPaymentResponse)
@@ -103,7 +151,7 @@ func (c *Client) RefundPayment(
 		ctx,
 		"refund_payment",
 		"post",
-		c.openEndpoint+"/v3/payment/refund",
+		c.openURL("/v3/payment/refund"),
 		req,
 		resp,
 	); err != nil {
@@ -111,3 +159,31 @@ func (c *Client) RefundPayment(
 	}
 	return resp, nil
 }
+
+// Refund :
+type Refund struct {
+	ID            string       `json:"id"`
+	TransactionID string       `json:"transactionId"`
+	Type          RefundType   `json:"type"`
+	CurrencyType  CurrencyType `json:"currencyType"`
+	Amount        Amount       `json:"amount"`
+	Reason        string       `json:"reason"`
+	Status        string       `json:"status"`
+	CreatedAt     Time         `json:"createdAt"`
+}
+
+// ListRefunds : returns all the refunds issued against the transaction
+func (c *Client) ListRefunds(ctx context.Context, transactionID string) ([]Refund, error) {
+	items := make([]Refund, 0)
+	if _, err := c.doUnwrap(
+		ctx,
+		"list_refunds",
+		"get",
+		c.openURL("/v3/payment/transaction/"+url.PathEscape(transactionID)+"/refunds"),
+		nil,
+		&items,
+	); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
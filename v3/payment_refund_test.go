@@ -0,0 +1,162 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundPayment(t *testing.T) {
+	pymt, err := ioutil.ReadFile("./sample/query_payment.json")
+	require.NoError(t, err)
+	refund, err := ioutil.ReadFile("./sample/refund_payment.json")
+	require.NoError(t, err)
+
+	var last RefundPaymentRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write(pymt)
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&last))
+		w.Write(refund)
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+
+	// full refund
+	{
+		req := RefundPaymentRequest{}
+		req.TransactionID = "200910090708300425661809"
+		req.Reason = "Not received goods"
+		_, err := client.RefundPayment(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, RefundTypeFull, last.Refund.Type)
+		require.Equal(t, Amount(2750), last.Refund.Amount)
+		require.Equal(t, CurrencyMYR, last.Refund.CurrencyType)
+	}
+
+	// partial refund
+	{
+		req := RefundPaymentRequest{}
+		req.TransactionID = "200910090708300425661809"
+		req.Refund.Amount = 1000
+		_, err := client.RefundPayment(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, RefundTypePartial, last.Refund.Type)
+		require.Equal(t, Amount(1000), last.Refund.Amount)
+	}
+
+	// refund amount exceeds the balance
+	{
+		req := RefundPaymentRequest{}
+		req.TransactionID = "200910090708300425661809"
+		req.Refund.Amount = 3000
+		_, err := client.RefundPayment(ctx, req)
+		require.Error(t, err)
+	}
+}
+
+func TestRefundBalance(t *testing.T) {
+	var posted int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			posted++
+			w.Write([]byte(`{"item":{},"code":"SUCCESS"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/payment/transaction/refunded":
+			w.Write([]byte(`{"item":{"order":{"amount":2750},"balanceAmount":0,"status":"FULL_REFUNDED"},"code":"SUCCESS"}`))
+		case "/v3/payment/transaction/zero-balance":
+			w.Write([]byte(`{"item":{"order":{"amount":2750},"balanceAmount":0,"status":"SUCCESS"},"code":"SUCCESS"}`))
+		case "/v3/payment/transaction/partially-refunded":
+			w.Write([]byte(`{"item":{"order":{"amount":2750},"balanceAmount":750,"status":"SUCCESS"},"code":"SUCCESS"}`))
+		case "/v3/payment/transaction/no-balance":
+			w.Write([]byte(`{"item":{"order":{"amount":2750},"status":"SUCCESS"},"code":"SUCCESS"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	refund := func(transactionID string, typ RefundType, amount Amount) error {
+		req := RefundPaymentRequest{TransactionID: transactionID}
+		req.Refund.Type = typ
+		req.Refund.Amount = amount
+		_, err := client.RefundPayment(ctx, req)
+		return err
+	}
+
+	// the fully refunded transaction is never refunded again
+	require.ErrorIs(t, refund("refunded", "", 0), ErrPaymentAlreadyRefunded)
+	require.ErrorIs(t, refund("zero-balance", "", 0), ErrPaymentAlreadyRefunded)
+	require.ErrorIs(t, refund("zero-balance", "", 100), ErrPaymentAlreadyRefunded)
+
+	require.Error(t, refund("partially-refunded", "", 1000))
+	require.Error(t, refund("partially-refunded", "", -100))
+	// the full refund must refund the whole order
+	require.Error(t, refund("partially-refunded", RefundTypeFull, 0))
+	require.Error(t, refund("no-balance", RefundTypeFull, 1000))
+	require.Equal(t, 0, posted)
+
+	require.NoError(t, refund("partially-refunded", "", 0))
+	require.NoError(t, refund("no-balance", RefundTypeFull, 2750))
+	require.Equal(t, 2, posted)
+}
+
+func TestRefundInsufficientBalance(t *testing.T) {
+	pymt, err := ioutil.ReadFile("./sample/query_payment.json")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write(pymt)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":"INSUFFICIENT_BALANCE","message":"Insufficient balance"}}`))
+	}))
+	defer srv.Close()
+
+	req := RefundPaymentRequest{}
+	req.TransactionID = "200910090708300425661809"
+	req.Refund.Amount = 1000
+	_, err = mockServerClient(srv).RefundPayment(context.Background(), req)
+	require.True(t, errors.Is(err, ErrInsufficientBalance))
+	require.False(t, errors.Is(err, ErrPaymentAlreadyRefunded))
+}
+
+func TestListRefunds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/payment/transaction/200910090708300425661809/refunds", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[
+			{"id":"1","transactionId":"200910090708300425661809","type":"PARTIAL","amount":100,"reason":"damaged","status":"SUCCESS","createdAt":"2021-03-04T05:06:07Z"},
+			{"id":"2","transactionId":"200910090708300425661809","type":"PARTIAL","amount":250,"reason":"missing","status":"SUCCESS","createdAt":"2021-03-05T05:06:07Z"}
+		],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	refunds, err := mockServerClient(srv).ListRefunds(context.Background(), "200910090708300425661809")
+	require.NoError(t, err)
+	require.Len(t, refunds, 2)
+	require.Equal(t, Amount(100), refunds[0].Amount)
+	require.Equal(t, "missing", refunds[1].Reason)
+	require.Equal(t, 2021, refunds[1].CreatedAt.Year())
+}
@@ -0,0 +1,34 @@
+package rm
+
+import "context"
+
+// VoidRequest :
+type VoidRequest struct {
+	TransactionID string `json:"transactionId"`
+}
+
+// VoidResponse :
+type VoidResponse struct {
+	Item Transaction `json:"item"`
+	Code string      `json:"code"`
+}
+
+// VoidTransaction : void the transaction before it's settled, use RefundPayment instead
+// if the transaction is settled. It returns ErrAlreadySettled if the transaction is settled.
+func (c *Client) VoidTransaction(
+	ctx context.Context,
+	transactionID string,
+) (*VoidResponse, error) {
+	resp := new(VoidResponse)
+	if err := c.do(
+		ctx,
+		"void_transaction",
+		"post",
+		c.openURL("/v3/payment/reverse"),
+		VoidRequest{TransactionID: transactionID},
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,55 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoidTransaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := VoidRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "/v3/payment/reverse", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.TransactionID == "settled" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"TRANSACTION_ALREADY_SETTLED","message":"Transaction already settled"}}`))
+			return
+		}
+		w.Write([]byte(`{"item":{"transactionId":"` + req.TransactionID + `","status":"REVERSED"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+
+	resp, err := client.VoidTransaction(ctx, "200910090708300425661809")
+	require.NoError(t, err)
+	require.Equal(t, "200910090708300425661809", resp.Item.TransactionID)
+	require.Equal(t, PaymentStatusReserved, resp.Item.Status)
+
+	_, err = client.VoidTransaction(ctx, "settled")
+	require.ErrorIs(t, err, ErrAlreadySettled)
+}
+
+func TestEmptyResponseBody(t *testing.T) {
+	for _, body := range []string{"", "null", " \n"} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+
+		resp, err := mockServerClient(srv).VoidTransaction(context.Background(), "200910090708300425661809")
+		require.NoError(t, err, "body %q", body)
+		require.NotNil(t, resp)
+		srv.Close()
+	}
+}
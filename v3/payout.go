@@ -0,0 +1,65 @@
+package rm
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// PayoutOptions :
+type PayoutOptions struct {
+	Offset int
+	// Limit is the number of payouts per page, default to 100
+	Limit int
+	// StartAt and EndAt filter the payouts by the settlement date, zero value means no filter
+	StartAt time.Time
+	EndAt   time.Time
+}
+
+// Payout :
+type Payout struct {
+	ID            string `json:"id"`
+	CurrencyType  string `json:"currencyType"`
+	Amount        uint   `json:"amount"`
+	Fee           uint   `json:"fee"`
+	NetAmount     uint   `json:"netAmount"`
+	BankReference string `json:"bankReference"`
+	Status        string `json:"status"`
+	SettledAt     Time   `json:"settledAt"`
+	CreatedAt     Time   `json:"createdAt"`
+	UpdatedAt     Time   `json:"updatedAt"`
+}
+
+// ListPayouts : returns a page of the payouts
+func (c *Client) ListPayouts(ctx context.Context, opts PayoutOptions) ([]Payout, Pagination, error) {
+	params := url.Values{}
+	if !opts.StartAt.IsZero() {
+		params.Set("startAt", opts.StartAt.UTC().Format(time.RFC3339))
+	}
+	if !opts.EndAt.IsZero() {
+		params.Set("endAt", opts.EndAt.UTC().Format(time.RFC3339))
+	}
+
+	items := make([]Payout, 0)
+	page, err := c.list(ctx, "list_payouts", c.openURL("/v3/payout"), params, opts.Offset, opts.Limit, &items)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return items, page, nil
+}
+
+// GetPayout :
+func (c *Client) GetPayout(ctx context.Context, payoutID string) (*Payout, error) {
+	item := new(Payout)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_payout",
+		"get",
+		c.openURL("/v3/payout/"+url.PathEscape(payoutID)),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,59 @@
+package rm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/v3/payout":
+			q := r.URL.Query()
+			require.Equal(t, "10", q.Get("offset"))
+			require.Equal(t, "5", q.Get("limit"))
+			require.Equal(t, "2021-02-28T16:00:00Z", q.Get("startAt"))
+			require.Equal(t, "2021-03-31T16:00:00Z", q.Get("endAt"))
+			w.Write([]byte(`{"items":[{"id":"1","amount":10000,"fee":150,"netAmount":9850,"status":"SETTLED"}],"code":"SUCCESS","meta":{"count":1,"total":11}}`))
+		case "/v3/payout/a%2Fb":
+			w.Write([]byte(`{"item":{"id":"a/b","currencyType":"MYR","amount":10000,"bankReference":"ref-1","status":"SETTLED"},"code":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"NOT_FOUND","message":"Payout not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+		myt    = time.FixedZone("MYT", 8*60*60)
+	)
+	payouts, page, err := client.ListPayouts(ctx, PayoutOptions{
+		Offset:  10,
+		Limit:   5,
+		StartAt: time.Date(2021, 3, 1, 0, 0, 0, 0, myt),
+		EndAt:   time.Date(2021, 4, 1, 0, 0, 0, 0, myt),
+	})
+	require.NoError(t, err)
+	require.Len(t, payouts, 1)
+	require.Equal(t, uint(9850), payouts[0].NetAmount)
+	require.Equal(t, Pagination{Offset: 10, Limit: 5, Total: 11}, page)
+
+	payout, err := client.GetPayout(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, "a/b", payout.ID)
+	require.Equal(t, "ref-1", payout.BankReference)
+	require.Equal(t, "SETTLED", payout.Status)
+
+	_, err = client.GetPayout(ctx, "2")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "NOT_FOUND", apiErr.Code)
+}
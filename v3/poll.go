@@ -0,0 +1,59 @@
+package rm
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PollOptions :
+type PollOptions struct {
+	// Interval is the delay before the first poll, default to 1s, it's doubled after
+	// every poll until MaxInterval, default to 10s
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+// IsTerminal : reports whether the payment is no longer in process, e.g. success, failed or cancelled
+func (s PaymentStatus) IsTerminal() bool {
+	return s != "" && s != PaymentStatusInProcess
+}
+
+// WaitForPayment : polls the transaction of the order until it's terminal or
+// the context is done, ErrTransactionNotFound is tolerated since the transaction is only
+// created after the customer scans the QR code. The requests respect Config.RateLimiter.
+func (c *Client) WaitForPayment(
+	ctx context.Context,
+	orderID string,
+	opts PollOptions,
+) (*Transaction, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 10 * time.Second
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+
+		tx, err := c.GetTransactionByOrderID(ctx, orderID)
+		if err != nil && !errors.Is(err, ErrTransactionNotFound) {
+			return nil, err
+		}
+		if tx != nil && tx.Status.IsTerminal() {
+			return tx, nil
+		}
+
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package rm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForPayment(t *testing.T) {
+	var polls, pending int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch n := atomic.AddInt32(&polls, 1); {
+		case atomic.LoadInt32(&pending) == 1:
+			w.Write([]byte(`{"item":{"transactionId":"1","status":"IN_PROCESS"},"code":"SUCCESS"}`))
+		case n == 1:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"TRANSACTION_NOT_FOUND"}}`))
+		case n < 4:
+			w.Write([]byte(`{"item":{"transactionId":"1","status":"IN_PROCESS"},"code":"SUCCESS"}`))
+		default:
+			w.Write([]byte(`{"item":{"transactionId":"1","status":"SUCCESS"},"code":"SUCCESS"}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	opts := PollOptions{Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+	tx, err := client.WaitForPayment(context.Background(), "1234", opts)
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusSuccess, tx.Status)
+	require.Equal(t, int32(4), atomic.LoadInt32(&polls))
+
+	// the payment is never completed
+	atomic.StoreInt32(&pending, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.WaitForPayment(ctx, "1234", opts)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
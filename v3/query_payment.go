@@ -2,6 +2,13 @@ package rm
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -34,13 +41,13 @@ type GetPaymentByOrderIDResponse struct {
 			ID     string `json:"id"`
 			Title  string `json:"title"`
 			Detail string `json:"detail"`
-			Amount uint   `json:"amount"`
+			Amount Amount `json:"amount"`
 		} `json:"order"`
 		TerminalID string `json:"terminalId"`
 		Payee      struct {
 		} `json:"payee"`
 		CurrencyType  string        `json:"currencyType"`
-		BalanceAmount uint          `json:"balanceAmount"`
+		BalanceAmount Amount        `json:"balanceAmount"`
 		Platform      string        `json:"platform"`
 		Method        string        `json:"method"`
 		TransactionAt time.Time     `json:"transactionAt"`
@@ -63,7 +70,7 @@ func (c *Client) GetPaymentByOrderID(
 		ctx,
 		"query_payment_by_order_id",
 		"get",
-		c.openEndpoint+"/v3/payment/transaction/order/"+orderID,
+		c.openURL("/v3/payment/transaction/order/"+orderID),
 		nil,
 		resp,
 	); err != nil {
@@ -101,13 +108,13 @@ type GetPaymentByTransactionIDResponse struct {
 			ID     string `json:"id"`
 			Title  string `json:"title"`
 			Detail string `json:"detail"`
-			Amount uint   `json:"amount"`
+			Amount Amount `json:"amount"`
 		} `json:"order"`
 		TerminalID string `json:"terminalId"`
 		Payee      struct {
 		} `json:"payee"`
 		CurrencyType  string        `json:"currencyType"`
-		BalanceAmount int           `json:"balanceAmount"`
+		BalanceAmount Amount        `json:"balanceAmount"`
 		Voucher       interface{}   `json:"voucher"`
 		Platform      string        `json:"platform"`
 		Method        string        `json:"method"`
@@ -132,7 +139,7 @@ func (c *Client) GetPaymentByTransactionID(
 		ctx,
 		"query_payment_by_transaction_id",
 		"get",
-		c.openEndpoint+"/v3/payment/transaction/"+transactionID,
+		c.openURL("/v3/payment/transaction/"+transactionID),
 		nil,
 		resp,
 	); err != nil {
@@ -150,7 +157,7 @@ type GetPaymentByCheckoutIDResponse struct {
 			Detail         string `json:"detail"`
 			AdditionalData string `json:"additionalData"`
 			CurrencyType   string `json:"currencyType"`
-			Amount         uint   `json:"amount"`
+			Amount         Amount `json:"amount"`
 		} `json:"order"`
 		Type          string    `json:"type"`
 		TransactionID string    `json:"transactionId"`
@@ -177,7 +184,7 @@ func (c *Client) GetPaymentByCheckoutID(
 		ctx,
 		"query_payment_by_checkout_id",
 		"get",
-		c.openEndpoint+"/v3/payment/online?checkoutId="+checkoutID,
+		c.openURL("/v3/payment/online?checkoutId="+checkoutID),
 		nil,
 		resp,
 	); err != nil {
@@ -185,3 +192,248 @@ func (c *Client) GetPaymentByCheckoutID(
 	}
 	return resp, nil
 }
+
+// Transaction :
+type Transaction struct {
+	TransactionID string `json:"transactionId"`
+	ReferenceID   string `json:"referenceId"`
+	ReceiptNo     string `json:"receiptNo"`
+	Order         struct {
+		ID     string `json:"id"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+		Amount Amount `json:"amount"`
+	} `json:"order"`
+	Payee struct {
+		UserID string `json:"userId"`
+	} `json:"payee"`
+	CurrencyType  string        `json:"currencyType"`
+	BalanceAmount Amount        `json:"balanceAmount"`
+	Platform      string        `json:"platform"`
+	Method        string        `json:"method"`
+	TransactionAt Time          `json:"transactionAt"`
+	Type          PaymentType   `json:"type"`
+	Status        PaymentStatus `json:"status"`
+	Region        string        `json:"region"`
+	// the fees charged by RM, NetAmount is the amount settled to the merchant
+	PlatformCharge   Amount `json:"platformCharge"`
+	MDRCharge        Amount `json:"mdrCharge"`
+	NetAmount        Amount `json:"netAmount"`
+	SettlementStatus string `json:"settlementStatus"`
+	// the cross-border payment, e.g. WeChat Pay and Alipay, is charged in the foreign
+	// currency and settled in MYR
+	ForeignAmount    Amount       `json:"foreignAmount"`
+	ForeignCurrency  CurrencyType `json:"foreignCurrency"`
+	ExchangeRate     json.Number  `json:"exchangeRate"`
+	SettlementAmount Amount       `json:"settlementAmount"`
+	CreatedAt        Time         `json:"createdAt"`
+	UpdatedAt        Time         `json:"updatedAt"`
+}
+
+// IsCrossBorder : reports whether the transaction is charged in the foreign currency
+func (tx Transaction) IsCrossBorder() bool {
+	return tx.ForeignCurrency != ""
+}
+
+// Fee : returns the total fee charged for the transaction
+func (tx Transaction) Fee() Amount {
+	return tx.PlatformCharge + tx.MDRCharge
+}
+
+// GetTransactionByOrderID :
+func (c *Client) GetTransactionByOrderID(
+	ctx context.Context,
+	orderID string,
+) (*Transaction, error) {
+	item := new(Transaction)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_transaction_by_order_id",
+		"get",
+		c.openURL("/v3/payment/transaction/order/"+url.PathEscape(orderID)),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// batchConcurrency is the number of concurrent requests of the batch query
+const batchConcurrency = 5
+
+// BatchError : the errors of the batch query keyed by the id which failed
+type BatchError map[string]error
+
+func (e BatchError) Error() string {
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = id + ": " + e[id].Error()
+	}
+	return fmt.Sprintf("rm: %d of the queries failed (%s)", len(e), strings.Join(msgs, "; "))
+}
+
+// GetTransactionsByOrderIDs : queries the transactions concurrently, the requests
+// are still paced by Config.RateLimiter. The transactions found are returned along with
+// BatchError if some of the queries failed.
+func (c *Client) GetTransactionsByOrderIDs(
+	ctx context.Context,
+	orderIDs []string,
+) (map[string]*Transaction, error) {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		txs  = make(map[string]*Transaction, len(orderIDs))
+		errs = make(BatchError)
+		sem  = make(chan struct{}, batchConcurrency)
+		seen = make(map[string]bool, len(orderIDs))
+	)
+
+	for _, orderID := range orderIDs {
+		if seen[orderID] {
+			continue
+		}
+		seen[orderID] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(orderID string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			tx, err := c.GetTransactionByOrderID(ctx, orderID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[orderID] = err
+				return
+			}
+			txs[orderID] = tx
+		}(orderID)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return txs, errs
+	}
+	return txs, nil
+}
+
+// GetTransaction : returns the transaction by RM's transaction id
+func (c *Client) GetTransaction(
+	ctx context.Context,
+	transactionID string,
+) (*Transaction, error) {
+	item := new(Transaction)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_transaction",
+		"get",
+		c.openURL("/v3/payment/transaction/"+url.PathEscape(transactionID)),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ListTransactionsOptions :
+type ListTransactionsOptions struct {
+	StoreID string
+	// From and To filter the transactions within the window, zero value means unbounded
+	From   time.Time
+	To     time.Time
+	Status PaymentStatus
+	Offset int
+	// Limit is the number of transactions requested per page
+	Limit int
+}
+
+func (opts ListTransactionsOptions) values(storeID string) url.Values {
+	params := url.Values{}
+	if opts.StoreID != "" {
+		storeID = opts.StoreID
+	}
+	if storeID != "" {
+		params.Set("storeId", storeID)
+	}
+	if !opts.From.IsZero() {
+		params.Set("startAt", opts.From.UTC().Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		params.Set("endAt", opts.To.UTC().Format(time.RFC3339))
+	}
+	if opts.Status != "" {
+		params.Set("status", string(opts.Status))
+	}
+	if opts.Offset > 0 {
+		params.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	return params
+}
+
+// ListTransactions : returns a page of the transactions
+func (c *Client) ListTransactions(
+	ctx context.Context,
+	opts ListTransactionsOptions,
+	reqOpts ...RequestOption,
+) ([]Transaction, Pagination, error) {
+	params := opts.values(c.storeIDOf(newRequestOptions(reqOpts)))
+
+	txs := make([]Transaction, 0)
+	page, err := c.list(
+		ctx,
+		"list_transactions",
+		c.openURL("/v3/payment/transactions"),
+		params,
+		opts.Offset,
+		opts.Limit,
+		&txs,
+		reqOpts...,
+	)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return txs, page, nil
+}
+
+// ListAllTransactions : returns all the transactions, it will walk through every page
+func (c *Client) ListAllTransactions(
+	ctx context.Context,
+	opts ListTransactionsOptions,
+	reqOpts ...RequestOption,
+) ([]Transaction, error) {
+	params := opts.values(c.storeIDOf(newRequestOptions(reqOpts)))
+
+	txs := make([]Transaction, 0)
+	if err := c.iterate(
+		ctx,
+		"list_transactions",
+		c.openURL("/v3/payment/transactions"),
+		params,
+		func(b json.RawMessage) error {
+			var tx Transaction
+			if err := unmarshalJSON(b, &tx); err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+			return nil
+		},
+		reqOpts...,
+	); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
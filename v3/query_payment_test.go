@@ -0,0 +1,212 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransactionByOrderID(t *testing.T) {
+	srv := mockFileServer(t, "./sample/query_payment.json")
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	tx, err := client.GetTransactionByOrderID(context.Background(), "128200910090623482313")
+	require.NoError(t, err)
+	require.Equal(t, "200910090708300425661809", tx.TransactionID)
+	require.Equal(t, "128200910090623482313", tx.Order.ID)
+	require.Equal(t, Amount(2750), tx.Order.Amount)
+	require.Equal(t, "BOOST", tx.Method)
+	require.Equal(t, PaymentStatusSuccess, tx.Status)
+	require.Equal(t, PaymentTypeWeb, tx.Type)
+}
+
+func TestGetTransactionByOrderIDEscape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/payment/transaction/order/a%2Fb%3Fc%23d", r.URL.EscapedPath())
+		require.Empty(t, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"transactionId":"1","order":{"id":"a/b?c#d"}},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	tx, err := client.GetTransactionByOrderID(context.Background(), "a/b?c#d")
+	require.NoError(t, err)
+	require.Equal(t, "a/b?c#d", tx.Order.ID)
+}
+
+func TestTransactionFee(t *testing.T) {
+	var tx Transaction
+	require.NoError(t, json.Unmarshal([]byte(`{"transactionId":"1","order":{"amount":10000},"platformCharge":50,"mdrCharge":120,"netAmount":9830,"settlementStatus":"SETTLED"}`), &tx))
+	require.Equal(t, Amount(50), tx.PlatformCharge)
+	require.Equal(t, Amount(120), tx.MDRCharge)
+	require.Equal(t, Amount(170), tx.Fee())
+	require.Equal(t, tx.Order.Amount-tx.Fee(), tx.NetAmount)
+	require.Equal(t, "SETTLED", tx.SettlementStatus)
+}
+
+func TestCrossBorderTransaction(t *testing.T) {
+	var tx Transaction
+	require.NoError(t, json.Unmarshal([]byte(`{"transactionId":"1","method":"WECHATPAY","currencyType":"MYR","foreignAmount":1500,"foreignCurrency":"CNY","exchangeRate":"0.61234567","settlementAmount":918}`), &tx))
+	require.True(t, tx.IsCrossBorder())
+	require.Equal(t, Amount(1500), tx.ForeignAmount)
+	require.Equal(t, CurrencyCNY, tx.ForeignCurrency)
+	require.Equal(t, json.Number("0.61234567"), tx.ExchangeRate)
+	require.Equal(t, Amount(918), tx.SettlementAmount)
+
+	tx = Transaction{}
+	require.NoError(t, json.Unmarshal([]byte(`{"transactionId":"2","exchangeRate":0.5}`), &tx))
+	require.Equal(t, json.Number("0.5"), tx.ExchangeRate)
+	require.False(t, tx.IsCrossBorder())
+}
+
+func TestGetTransactionsByOrderIDs(t *testing.T) {
+	var inflight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		orderID := strings.TrimPrefix(r.URL.Path, "/v3/payment/transaction/order/")
+		w.Header().Set("Content-Type", "application/json")
+		if orderID == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"TRANSACTION_NOT_FOUND"}}`))
+			return
+		}
+		w.Write([]byte(`{"item":{"transactionId":"tx-` + orderID + `","order":{"id":"` + orderID + `"}},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	ids := []string{"missing"}
+	for i := 0; i < 20; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	ids = append(ids, "1")
+
+	txs, err := mockServerClient(srv).GetTransactionsByOrderIDs(context.Background(), ids)
+	require.Len(t, txs, 20)
+	require.Equal(t, "tx-7", txs["7"].TransactionID)
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(batchConcurrency))
+
+	var batchErr BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr, 1)
+	require.ErrorIs(t, batchErr["missing"], ErrTransactionNotFound)
+}
+
+func TestGetTransaction(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/payment/transaction/200910090708300425661809", r.URL.Path)
+		b, _ := ioutil.ReadFile("./sample/query_payment.json")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	tx, err := client.GetTransaction(context.Background(), "200910090708300425661809")
+	require.NoError(t, err)
+	require.Equal(t, "200910090708300425661809", tx.TransactionID)
+	require.Equal(t, "128200910090623482313", tx.Order.ID)
+}
+
+func TestListTransactions(t *testing.T) {
+	const total = 5
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/payment/transactions", r.URL.Path)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		items := make([]Transaction, 0)
+		for i := offset; i < total && i < offset+limit; i++ {
+			tx := Transaction{}
+			tx.TransactionID = strconv.Itoa(i)
+			items = append(items, tx)
+		}
+
+		resp := map[string]interface{}{
+			"items":      items,
+			"code":       ResponseSuccess,
+			"pagination": Pagination{Offset: offset, Limit: limit, Total: total},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	txs, err := client.ListAllTransactions(context.Background(), ListTransactionsOptions{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, txs, total)
+	for i, tx := range txs {
+		require.Equal(t, strconv.Itoa(i), tx.TransactionID)
+	}
+
+	txs, page, err := client.ListTransactions(context.Background(), ListTransactionsOptions{Offset: 2, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, "2", txs[0].TransactionID)
+	require.Equal(t, Pagination{Offset: 2, Limit: 2, Total: total}, page)
+}
+
+func TestListTransactionsFilter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		require.Equal(t, "store-1", q.Get("storeId"))
+		require.Equal(t, "2021-03-01T00:00:00Z", q.Get("startAt"))
+		require.Equal(t, "2021-03-01T16:00:00Z", q.Get("endAt"))
+		require.Equal(t, "SUCCESS", q.Get("status"))
+		require.Equal(t, "0", q.Get("offset"))
+		require.Equal(t, "100", q.Get("limit"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	myt := time.FixedZone("MYT", 8*60*60)
+	client := mockServerClient(srv)
+	_, _, err := client.ListTransactions(context.Background(), ListTransactionsOptions{
+		StoreID: "store-1",
+		From:    time.Date(2021, 3, 1, 8, 0, 0, 0, myt),
+		To:      time.Date(2021, 3, 2, 0, 0, 0, 0, myt),
+		Status:  PaymentStatusSuccess,
+	})
+	require.NoError(t, err)
+}
+
+func TestListTransactionsRequestOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "store-2", r.URL.Query().Get("storeId"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var raw []byte
+	client := mockServerClient(srv)
+	_, _, err := client.ListTransactions(context.Background(), ListTransactionsOptions{}, WithStoreID("store-2"), WithRawResponse(&raw))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"items":[],"code":"SUCCESS"}`, string(raw))
+
+	raw = nil
+	_, err = client.ListAllTransactions(context.Background(), ListTransactionsOptions{}, WithStoreID("store-2"), WithRawResponse(&raw))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"items":[],"code":"SUCCESS"}`, string(raw))
+}
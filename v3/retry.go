@@ -0,0 +1,113 @@
+package rm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shouldRetry reports whether the request should be sent again.
+// Requests rejected by the rate limit (429) are always retried.
+// Idempotent requests, including the requests with idempotency key, are retried
+// on 502, 503, 504 and connection errors, while non-idempotent requests are only
+// retried when the connection couldn't be established, which means nothing was sent to RM.
+func (c *Client) shouldRetry(attempt int, method string, hasIdempotencyKey bool, res *http.Response, err error) bool {
+	if attempt >= c.maxRetries {
+		return false
+	}
+
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		return hasIdempotencyKey || isIdempotent(method)
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return hasIdempotencyKey || isIdempotent(method)
+	}
+	return false
+}
+
+// maxBackoff caps the exponential delay of the retry
+const maxBackoff = 30 * time.Second
+
+// backoff returns the exponential delay with jitter of the attempt, it's capped at maxBackoff
+func (c *Client) backoff(attempt int) time.Duration {
+	d := maxBackoff
+	// the delay is doubled until it reaches the cap, so the shift never overflows
+	if c.retryBackoff < maxBackoff {
+		d = c.retryBackoff
+		for i := 0; i < attempt && d > 0 && d < maxBackoff; i++ {
+			d <<= 1
+		}
+		if d > maxBackoff {
+			d = maxBackoff
+		}
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// maxRetryAfter caps the delay of the `Retry-After` header, so the misbehaving
+// server or proxy can't make the client sleep for hours
+const maxRetryAfter = time.Minute
+
+// retryAfter returns the delay of the `Retry-After` header of 429 response,
+// the header is either the seconds or the http date, it's capped at maxRetryAfter
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil || res.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	v := strings.TrimSpace(res.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		d = time.Duration(secs) * time.Second
+		// the huge value overflows the duration
+		if secs > int(maxRetryAfter/time.Second) {
+			d = maxRetryAfter
+		}
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+	} else {
+		return 0, false
+	}
+	if d > maxRetryAfter {
+		d = maxRetryAfter
+	}
+	return d, true
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,218 @@
+package rm
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestRetry(t *testing.T) {
+	var counter int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&counter, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := mockServerClient(srv)
+	client.maxRetries = 3
+	client.retryBackoff = time.Millisecond
+
+	resp, err := client.GetStores(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ResponseSuccess, resp.Code)
+	require.Equal(t, int32(3), atomic.LoadInt32(&counter))
+
+	// non-idempotent request shouldn't retry on 503
+	atomic.StoreInt32(&counter, 0)
+	_, err = client.CreateTransactionQR(ctx, CreateTransactionQRRequest{})
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&counter))
+
+	// retry is disabled by default
+	atomic.StoreInt32(&counter, 0)
+	client.maxRetries = 0
+	_, err = client.GetStores(ctx)
+	require.Error(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&counter))
+}
+
+func TestRetryWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"id":"1","qrCodeUrl":"https://www.google.com"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := mockServerClient(srv)
+	client.maxRetries = 2
+	client.retryBackoff = time.Millisecond
+
+	// the key is generated and reused across attempts
+	_, err := client.CreateDynamicQR(ctx, CreateQRRequest{Amount: 100})
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.NotEmpty(t, keys[0])
+	require.Equal(t, keys[0], keys[1])
+
+	keys = nil
+	client.maxRetries = 0
+	_, err = client.CreateDynamicQR(ctx, CreateQRRequest{Amount: 100}, WithIdempotencyKey("order-1"))
+	require.Error(t, err)
+	require.Equal(t, []string{"order-1"}, keys)
+}
+
+func TestRetryAfter(t *testing.T) {
+	var counter int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&counter, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"id":"1"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.maxRetries = 1
+	client.retryBackoff = time.Hour
+	client.rateLimiter = rate.NewLimiter(rate.Every(time.Millisecond), 1)
+
+	// rate limited requests are retried after `Retry-After` even it's non-idempotent
+	_, err := client.CreateTransactionQR(context.Background(), CreateTransactionQRRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&counter))
+
+	for v, want := range map[string]time.Duration{
+		"30":                  30 * time.Second,
+		"120":                 maxRetryAfter,
+		"86400":               maxRetryAfter,
+		"9223372036854775807": maxRetryAfter,
+		time.Now().Add(24 * time.Hour).UTC().Format(http.TimeFormat): maxRetryAfter,
+	} {
+		d, ok := retryAfter(&http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": {v}},
+		})
+		require.True(t, ok, v)
+		require.Equal(t, want, d, v)
+	}
+
+	// the retry is skipped if the delay exceeds the deadline
+	atomic.StoreInt32(&counter, 0)
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&counter, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+	client.openEndpoint = limited.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = client.CreateTransactionQR(ctx, CreateTransactionQRRequest{})
+	require.Error(t, err)
+	require.NoError(t, ctx.Err())
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+	require.Equal(t, int32(1), atomic.LoadInt32(&counter))
+}
+
+func TestRetryResign(t *testing.T) {
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	pub, err := parsePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		now     = time.Unix(1630000000, 0)
+		nonces  = make(map[string]bool)
+		counter int32
+	)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts, _ := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		// RM rejects the timestamp outside of the 5 minutes window
+		if clock().Sub(time.Unix(ts, 0)) > 5*time.Minute {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		nonce := r.Header.Get("X-Nonce-Str")
+		require.False(t, nonces[nonce], "nonce is reused")
+		nonces[nonce] = true
+
+		endpoint := "http://" + r.Host + r.URL.RequestURI()
+		require.NoError(t, verifySignature(r.Header, pub, func(signType string) []string {
+			return signParams("", "get", endpoint, nonce, r.Header.Get("X-Timestamp"), signType)
+		}))
+
+		if atomic.AddInt32(&counter, 1) == 1 {
+			// the backoff takes longer than the timestamp window
+			mu.Lock()
+			now = now.Add(6 * time.Minute)
+			mu.Unlock()
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.maxRetries = 1
+	client.retryBackoff = time.Millisecond
+	client.now = clock
+
+	_, err = client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&counter))
+	require.Len(t, nonces, 2)
+}
+
+func TestBackoff(t *testing.T) {
+	client := mockRmClient()
+	client.maxRetries = 1000
+	client.retryBackoff = 200 * time.Millisecond
+
+	d := client.backoff(0)
+	require.True(t, d >= 100*time.Millisecond && d <= 200*time.Millisecond, d)
+
+	// the delay is capped instead of overflowing
+	for attempt := 0; attempt < client.maxRetries; attempt++ {
+		d := client.backoff(attempt)
+		require.True(t, d >= 0 && d <= maxBackoff, "attempt %d: %v", attempt, d)
+	}
+	d = client.backoff(client.maxRetries)
+	require.True(t, d >= maxBackoff/2, d)
+
+	client.retryBackoff = time.Hour
+	require.True(t, client.backoff(0) <= maxBackoff)
+}
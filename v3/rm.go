@@ -2,36 +2,53 @@ package rm
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/clbanning/mxj"
 	"github.com/dchest/uniuri"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	jlog "github.com/opentracing/opentracing-go/log"
+	"github.com/tidwall/gjson"
 	"github.com/valyala/bytebufferpool"
 	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
 const (
 	ResponseSuccess = "SUCCESS"
+
+	defaultUserAgent = "rm-go-client/v3"
 )
 
+// signTypes is the supported hash algorithms of the signature
+var signTypes = map[crypto.Hash]string{
+	crypto.SHA256: "sha256",
+	crypto.SHA512: "sha512",
+}
+
 type Config struct {
 	ClientID     string
 	ClientSecret string
@@ -39,61 +56,237 @@ type Config struct {
 	PublicKey    []byte
 	StoreID      string
 	Sandbox      bool
-	TokenSource  oauth2.TokenSource
+	TokenSource  oauth2.TokenSource // see NewTokenSource to share the token across clients
 	Tracer       opentracing.Tracer
+	HTTPClient   *http.Client
+	// PrivateKeyPath, PrivateKeyReader and PublicKeyPath are the alternatives
+	// to load the keys, they're ignored if PrivateKey or PublicKey is provided
+	PrivateKeyPath   string
+	PrivateKeyReader io.Reader
+	PublicKeyPath    string
+	// OAuthEndpoint and OpenEndpoint override the endpoints derived from `Sandbox`
+	OAuthEndpoint string
+	OpenEndpoint  string
+	// UserAgent is sent on every request, default to "rm-go-client/v3"
+	UserAgent string
+	// RequestHook is invoked right before the request is sent
+	RequestHook func(*http.Request)
+	// ResponseHook is invoked right after the response body is read
+	ResponseHook func(*http.Response, []byte)
+	// SignRawBody signs the request body in the field order of the struct
+	// instead of sorting the keys
+	SignRawBody bool
+	// Logger receives the structured logs of every request, default to no-op
+	Logger Logger
+	// SignType is the hash algorithm used to sign the request, default to crypto.SHA256
+	SignType crypto.Hash
+	// MaxRetries is the maximum number of retries on transient failures,
+	// retry is disabled if it's zero
+	MaxRetries int
+	// RateLimiter paces the requests to respect RM's API quotas
+	RateLimiter *rate.Limiter
+	// RetryBackoff is the base delay of the exponential backoff, default to 200ms
+	RetryBackoff time.Duration
+	// NonceFunc and TimeFunc generate the `X-Nonce-Str` and `X-Timestamp` of the request,
+	// they're useful to produce the deterministic signature in tests
+	NonceFunc func() string
+	TimeFunc  func() time.Time
+	// Metrics observes the outcome of every request, default to no-op
+	Metrics MetricsObserver
+	// Token is the initial token of the default token source, e.g. the token shared by
+	// other instances, and TokenPersist is invoked whenever a new token is obtained.
+	// TokenPersist is called while the token is locked, it shouldn't call Client.Token.
+	Token        *oauth2.Token
+	TokenPersist func(*oauth2.Token)
+	// CorrelationHeaderFromContext derives the extra header (name, value) from the context,
+	// e.g. to forward the correlation id to RM, the header is skipped if either is empty
+	CorrelationHeaderFromContext func(ctx context.Context) (string, string)
+	// RequestTimeout bounds every request including the retries, it's composed with
+	// the deadline of the caller's context and the shorter one wins. It's disabled if zero.
+	RequestTimeout time.Duration
+	// Breaker fast-fails the requests during RM outages, e.g. NewBreaker(5, 30*time.Second),
+	// it's disabled if nil
+	Breaker Breaker
+	// ExtraHeaders are sent on every request, e.g. the key required by the API gateway,
+	// they never overwrite the headers set by the client such as Authorization and X-Signature
+	ExtraHeaders http.Header
+	// DryRun builds and signs every request and invokes RequestHook, but it's never sent,
+	// the request returns *DryRunError instead. The token isn't requested either, so
+	// the Authorization header is absent. See WithDryRun to dry run a single request.
+	DryRun bool
+	// TLSConfig is the TLS config of the default transport, e.g. to trust the private CA
+	// of the TLS-inspecting proxy by RootCAs. It conflicts with HTTPClient, configure
+	// the transport of HTTPClient instead if both are needed.
+	TLSConfig *tls.Config
+	// EnableHTTPTrace records the timing of DNS lookup, connect, TLS handshake and
+	// time to first byte of every attempt in the span, see HTTPTraceObserver for metrics
+	EnableHTTPTrace bool
+	// NextPrivateKey and PublicKeys support the key rotation without downtime.
+	// NextPrivateKey is preferred to sign the requests, the request rejected by
+	// SIGNATURE_MISMATCH is signed again by PrivateKey since RM might not have the new key yet.
+	// PublicKeys are tried along with PublicKey to verify the responses.
+	NextPrivateKey []byte
+	PublicKeys     [][]byte
 }
 
 // Client :
 type Client struct {
 	mu            sync.Mutex
+	tokenMu       sync.Mutex
+	storeMu       sync.Mutex
 	tracer        opentracing.Tracer
+	logger        Logger
+	httpClient    *http.Client
 	clientID      string
 	clientSecret  string
 	oauthEndpoint string
 	openEndpoint  string
 	token         *oauth2.Token
+	refreshExpiry time.Time
 	pk            *rsa.PrivateKey
+	signType      crypto.Hash
+	signRawBody   bool
 	pub           []byte
 	oauth2        oauth2.TokenSource
 	storeID       string
+	userAgent     string
+	requestHook   func(*http.Request)
+	responseHook  func(*http.Response, []byte)
+	maxRetries    int
+	retryBackoff  time.Duration
+	rateLimiter   *rate.Limiter
+	nonce         func() string
+	now           func() time.Time
+	metrics       MetricsObserver
+	tokenPersist  func(*oauth2.Token)
+	ctxHeader     func(context.Context) (string, string)
+	timeout       time.Duration
+	breaker       Breaker
+	extraHeaders  http.Header
+	dryRun        bool
+	httpTrace     bool
+	nextPK        *rsa.PrivateKey
+	pubs          [][]byte
 }
 
-// NewClient :
+// NewClient : create a new client, it will panic if the config is invalid.
+// Use NewClientWithError if you want to handle the error.
 func NewClient(cfg Config) *Client {
-	var (
-		c   = new(Client)
-		err error
-	)
-	c.clientID = cfg.ClientID
-	c.clientSecret = cfg.ClientSecret
+	c, err := NewClientWithError(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// NewClientWithError :
+func NewClientWithError(cfg Config) (*Client, error) {
+	cfg, err := cfg.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := newTokenClient(cfg)
 	c.tracer = &opentracing.NoopTracer{}
 	if cfg.Tracer != nil {
 		c.tracer = cfg.Tracer
 	}
-	c.oauthEndpoint = "https://oauth.revenuemonster.my"
-	c.openEndpoint = "https://open.revenuemonster.my"
-	if cfg.Sandbox {
-		c.oauthEndpoint = "https://sb-oauth.revenuemonster.my"
-		c.openEndpoint = "https://sb-open.revenuemonster.my"
+	c.logger = nopLogger{}
+	if cfg.Logger != nil {
+		c.logger = cfg.Logger
 	}
-
-	block, _ := pem.Decode(cfg.PrivateKey)
-	if block == nil {
-		panic("rm: invalid format of private key")
+	c.metrics = nopMetrics{}
+	if cfg.Metrics != nil {
+		c.metrics = cfg.Metrics
 	}
 
-	c.pk, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	block, _ := pem.Decode(cfg.PrivateKey)
+	c.pk, err = parsePrivateKey(block)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	c.signType = crypto.SHA256
+	if cfg.SignType != 0 {
+		c.signType = cfg.SignType
+	}
+	c.signRawBody = cfg.SignRawBody
 	c.pub = cfg.PublicKey
+	c.pubs = cfg.PublicKeys
+	if len(cfg.NextPrivateKey) > 0 {
+		block, _ := pem.Decode(cfg.NextPrivateKey)
+		c.nextPK, err = parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if cfg.TokenSource != nil {
 		c.oauth2 = cfg.TokenSource
 	} else {
 		c.oauth2 = c
 	}
+	c.ctxHeader = cfg.CorrelationHeaderFromContext
+	c.timeout = cfg.RequestTimeout
+	c.breaker = cfg.Breaker
+	c.extraHeaders = cfg.ExtraHeaders.Clone()
+	c.dryRun = cfg.DryRun
+	c.httpTrace = cfg.EnableHTTPTrace
 
 	c.storeID = cfg.StoreID
+	c.requestHook = cfg.RequestHook
+	c.responseHook = cfg.ResponseHook
+	c.maxRetries = cfg.MaxRetries
+	c.rateLimiter = cfg.RateLimiter
+	c.retryBackoff = 200 * time.Millisecond
+	if cfg.RetryBackoff > 0 {
+		c.retryBackoff = cfg.RetryBackoff
+	}
+	c.nonce = func() string { return uniuri.NewLen(25) }
+	if cfg.NonceFunc != nil {
+		c.nonce = cfg.NonceFunc
+	}
+	c.now = time.Now
+	if cfg.TimeFunc != nil {
+		c.now = cfg.TimeFunc
+	}
+	return c, nil
+}
+
+// newTokenClient returns the client which is only capable of requesting the token
+func newTokenClient(cfg Config) *Client {
+	c := new(Client)
+	c.clientID = cfg.ClientID
+	c.clientSecret = cfg.ClientSecret
+	c.httpClient = &http.Client{Timeout: 30 * time.Second}
+	if cfg.TLSConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg.TLSConfig.Clone()
+		c.httpClient.Transport = transport
+	}
+	if cfg.HTTPClient != nil {
+		c.httpClient = cfg.HTTPClient
+	}
+	c.oauthEndpoint = "https://oauth.revenuemonster.my"
+	c.openEndpoint = "https://open.revenuemonster.my"
+	if cfg.Sandbox {
+		c.oauthEndpoint = "https://sb-oauth.revenuemonster.my"
+		c.openEndpoint = "https://sb-open.revenuemonster.my"
+	}
+	if cfg.OAuthEndpoint != "" {
+		c.oauthEndpoint = strings.TrimSuffix(cfg.OAuthEndpoint, "/")
+	}
+	if cfg.OpenEndpoint != "" {
+		c.openEndpoint = strings.TrimSuffix(cfg.OpenEndpoint, "/")
+	}
+	c.userAgent = defaultUserAgent
+	if cfg.UserAgent != "" {
+		c.userAgent = cfg.UserAgent
+	}
+	c.token = cfg.Token
+	c.tokenPersist = cfg.TokenPersist
 	return c
 }
 
@@ -103,6 +296,19 @@ func (c *Client) SetTokenSource(src oauth2.TokenSource) {
 	c.oauth2 = src
 }
 
+// Ping : checks whether the credentials are still working by making
+// an authenticated request, it returns nil on success
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.GetMerchantProfile(ctx)
+	return err
+}
+
+func (c *Client) tokenSource() oauth2.TokenSource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.oauth2
+}
+
 func (c *Client) maybeStartSpanFromContext(ctx context.Context, operationName string) opentracing.Span {
 	var span opentracing.Span
 	if sp := opentracing.SpanFromContext(ctx); sp != nil {
@@ -120,31 +326,72 @@ func (c *Client) do(
 	endpoint string,
 	src interface{},
 	dest interface{},
-) error {
+	opts ...RequestOption,
+) (err error) {
 	var (
 		req    = new(http.Request)
 		b      = make([]byte, 0)
+		body   []byte
 		b64Str string
-		sign   string
-		err    error
+		status int
+		start  = time.Now()
 	)
 
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	o := newRequestOptions(opts)
+	operationName = o.operationNameOf(ctx, operationName)
+
 	span := c.maybeStartSpanFromContext(ctx, operationName)
 	defer span.Finish()
 
 	defer func() {
+		latency := time.Since(start)
+		c.metrics.ObserveRequest(operationName, status, latency, err)
+
+		fields := []interface{}{
+			"operation", operationName,
+			"method", method,
+			"endpoint", endpoint,
+			"status", status,
+			"latency", latency,
+		}
 		if err != nil {
 			ext.LogError(span, err)
+			c.logger.Error("rm: request failed", append(fields, "error", err)...)
+			return
 		}
+		c.logger.Debug("rm: request completed", fields...)
 	}()
 
-	if src != nil {
-		b, err = json.Marshal(src)
-		if err != nil {
-			return err
+	contentType := "application/json"
+	if o.contentType != "" {
+		contentType = o.contentType
+	}
+	// the body other than json is sent as it is, e.g. image upload
+	rawBody := !strings.Contains(contentType, "json")
+
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		b = v
+	case io.Reader:
+		b, err = ioutil.ReadAll(v)
+	default:
+		if rawBody {
+			return fmt.Errorf("rm: body of content type %q must be []byte or io.Reader, got %T", contentType, src)
 		}
+		b, err = json.Marshal(src)
+	}
+	if err != nil {
+		return err
 	}
 
+	// RM signs the method in lowercase, e.g. `method=delete`, while the request is sent in uppercase
 	method = strings.TrimSpace(strings.ToLower(method))
 	reqUrl, _ := url.Parse(endpoint)
 	req.Method = strings.ToUpper(method)
@@ -154,106 +401,605 @@ func (c *Client) do(
 	ext.HTTPMethod.Set(span, method)
 	ext.Component.Set(span, "rm-go-client")
 
-	span.LogFields(
-		jlog.String("http.request.body", string(b)),
+	if rawBody {
+		span.LogFields(jlog.Int("http.request.body_size", len(b)))
+		if method != "get" && len(b) > 0 {
+			body = b
+		}
+	} else {
+		span.LogFields(jlog.String("http.request.body", string(b)))
+		body, err = c.requestBody(method, b)
+		if err != nil {
+			return err
+		}
+	}
+	if body != nil {
+		b64Str = base64.StdEncoding.EncodeToString(body)
+	}
+
+	dryRun := c.dryRun || o.dryRun
+	req.Header = http.Header{
+		"Accept":       {"application/json"},
+		"Content-Type": {contentType},
+		"User-Agent":   {c.userAgent},
+	}
+
+	var tkn *oauth2.Token
+	if !dryRun {
+		tkn, err = tokenOf(ctx, c.tokenSource())
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+tkn.AccessToken)
+	}
+
+	// generate the idempotency key for payment creation if it might be retried,
+	// the same key is used for every attempt so RM returns the original resource
+	idempotencyKey := o.idempotencyKey
+	if idempotencyKey == "" && o.idempotent && c.maxRetries > 0 {
+		idempotencyKey = uniuri.NewLen(32)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	// the extra and context headers never override the headers set by the client
+	for k, v := range c.extraHeaders {
+		k = http.CanonicalHeaderKey(k)
+		if _, ok := req.Header[k]; ok || isSigningHeader(k) {
+			continue
+		}
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	if c.ctxHeader != nil {
+		if k, v := c.ctxHeader(ctx); k != "" && v != "" {
+			k = http.CanonicalHeaderKey(k)
+			if _, ok := req.Header[k]; !ok && !isSigningHeader(k) {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+
+	var (
+		res       *http.Response
+		refreshed bool
+		signKey   = c.pk
 	)
+	if c.nextPK != nil {
+		signKey = c.nextPK
+	}
+	for attempt := 0; ; attempt++ {
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
 
-	if len(b) > 0 &&
-		!bytes.Equal(b, []byte(`null`)) &&
-		!bytes.Equal(b, []byte(`{}`)) {
+		if c.rateLimiter != nil && !dryRun {
+			if err = c.rateLimiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
 
-		var (
-			buf = new(bytes.Buffer)
-			m   mxj.Map
-			js  []byte
+		// sign on every attempt, RM rejects the stale timestamp so the signature
+		// of the previous attempt can't be replayed after the backoff
+		if err = c.signRequest(req.Header, signKey, b64Str, method, endpoint); err != nil {
+			return err
+		}
+		span.LogFields(
+			jlog.String("nonce", req.Header.Get("X-Nonce-Str")),
+			jlog.String("timestamp", req.Header.Get("X-Timestamp")),
 		)
+		if o.signature != nil {
+			*o.signature = signatureOf(req.Header)
+		}
 
-		m, err = mxj.NewMapJson(b)
-		if err != nil {
-			return err
+		if c.requestHook != nil {
+			c.requestHook(req)
 		}
 
-		js, err = m.Json(true)
-		if err != nil {
+		if dryRun {
+			span.LogFields(jlog.String("event", "dry_run"))
+			return &DryRunError{Request: req.WithContext(ctx), Body: body}
+		}
+
+		// it's checked right before sending, so every request allowed is recorded by Done
+		if c.breaker != nil {
+			if err = c.breakerAllow(span); err != nil {
+				return err
+			}
+		}
+
+		reqCtx := ctx
+		var tracer *httpTracer
+		if c.httpTrace {
+			tracer = new(httpTracer)
+			reqCtx = httptrace.WithClientTrace(ctx, tracer.clientTrace())
+		}
+
+		res, err = c.httpClient.Do(req.WithContext(reqCtx))
+		if tracer != nil {
+			c.observeHTTPTrace(span, operationName, tracer.result())
+		}
+		if c.breaker != nil {
+			c.breakerDone(span, !isFailure(ctx, res, err))
+		}
+
+		// RM doesn't have the next key yet, sign with the current key again
+		if err == nil && signKey != c.pk && isSignatureMismatch(res) {
+			signKey = c.pk
+			span.LogFields(jlog.String("event", "signed_with_current_key"))
+			attempt--
+			continue
+		}
+
+		// the token might be revoked before it expires, refresh it and retry once
+		if err == nil && res.StatusCode == http.StatusUnauthorized && !refreshed {
+			if r, ok := c.tokenSource().(tokenRefresher); ok {
+				refreshed = true
+				io.Copy(ioutil.Discard, res.Body)
+				res.Body.Close()
+
+				if tkn, err = r.ForceTokenRefresh(ctx); err != nil {
+					return err
+				}
+				req.Header.Set("Authorization", "Bearer "+tkn.AccessToken)
+				span.LogFields(jlog.String("event", "token_refreshed"))
+				// it doesn't count as a retry
+				attempt--
+				continue
+			}
+		}
+
+		if ctx.Err() != nil || !c.shouldRetry(attempt, req.Method, idempotencyKey != "", res, err) {
+			break
+		}
+
+		// stop retrying if the next attempt will exceed the deadline
+		wait := c.backoff(attempt)
+		if d, ok := retryAfter(res); ok {
+			wait = d
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			break
+		}
+
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		span.LogFields(jlog.Int("retry", attempt+1))
+		if err = sleep(ctx, wait); err != nil {
 			return err
 		}
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			span.LogFields(jlog.String("event", "cancelled"))
+			err = ctxErr
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	status = res.StatusCode
+	ext.HTTPStatusCode.Set(span, uint16(res.StatusCode))
+	reqID := requestID(res.Header)
+	if reqID != "" {
+		span.SetTag("rm.request_id", reqID)
+	}
+
+	// skip to unmarshal if return status code is 204
+	if res.StatusCode == http.StatusNoContent {
+		if c.responseHook != nil {
+			c.responseHook(res, nil)
+		}
+		return nil
+	}
 
-		err = json.Compact(buf, js)
+	respBytes, err := readBody(res)
+	if err != nil {
+		return err
+	}
+
+	if c.responseHook != nil {
+		c.responseHook(res, respBytes)
+	}
+
+	span.LogFields(
+		jlog.String("http.response.body", string(respBytes)),
+	)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		rmErr := newError(res.StatusCode, reqUrl.String(), b, respBytes)
+		rmErr.RequestID = reqID
+		return rmErr
+	}
+
+	// some endpoints respond 200 without body, there is nothing to verify and decode
+	if trimmed := bytes.TrimSpace(respBytes); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		if o.rawResponse != nil {
+			*o.rawResponse = respBytes
+		}
+		return nil
+	}
+
+	if len(c.pub) > 0 || len(c.pubs) > 0 {
+		err = c.verifyResponse(method, endpoint, res.Header, respBytes)
 		if err != nil {
 			return err
 		}
+	}
 
-		req.Body = ioutil.NopCloser(buf)
-		b64Str = base64.StdEncoding.EncodeToString(buf.Bytes())
+	// only hand over the body once its signature is verified
+	if o.rawResponse != nil {
+		*o.rawResponse = respBytes
 	}
 
-	var tkn *oauth2.Token
-	tkn, err = c.oauth2.Token()
+	err = unmarshalJSON(respBytes, dest)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// openURL returns the url of the path on the open endpoint, the path carries
+// its own version prefix, e.g. "/v3/stores", since RM versions the endpoints separately
+func (c *Client) openURL(path string) string {
+	return joinURL(c.openEndpoint, path)
+}
+
+// oauthURL returns the url of the path on the oauth endpoint, e.g. "/v1/token"
+func (c *Client) oauthURL(path string) string {
+	return joinURL(c.oauthEndpoint, path)
+}
+
+func joinURL(endpoint, path string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// DoRaw : sends the signed request to the path of the open endpoint, e.g. "/v3/stores",
+// and returns the verbatim response body. It's useful for the endpoints which
+// aren't covered by the client yet, or to archive the original response.
+func (c *Client) DoRaw(
+	ctx context.Context,
+	method string,
+	path string,
+	src interface{},
+	opts ...RequestOption,
+) ([]byte, error) {
+	var raw []byte
+	var dest json.RawMessage
+	if err := c.do(
+		ctx,
+		"do_raw",
+		method,
+		c.openURL(path),
+		src,
+		&dest,
+		append(opts, WithRawResponse(&raw))...,
+	); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// signRequest sets the nonce, timestamp and signature headers of the request
+func (c *Client) signRequest(header http.Header, pk *rsa.PrivateKey, b64Str, method, endpoint string) error {
+	nonce := c.nonce()
+	ts := strconv.FormatInt(c.now().Unix(), 10)
+	signType := signTypes[c.signType]
+	sign, err := signData(c.signType, signParams(b64Str, method, endpoint, nonce, ts, signType), pk)
 	if err != nil {
 		return err
 	}
 
-	data := []string{}
-	randomStr := uniuri.NewLen(25)
-	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	header.Set("X-Nonce-Str", nonce)
+	header.Set("X-Signature", signType+" "+sign)
+	header.Set("X-Timestamp", ts)
+	return nil
+}
+
+// RequestSignature : the signing headers of the request
+type RequestSignature struct {
+	Nonce     string
+	Timestamp string
+	Signature string
+}
+
+func signatureOf(header http.Header) RequestSignature {
+	return RequestSignature{
+		Nonce:     header.Get("X-Nonce-Str"),
+		Timestamp: header.Get("X-Timestamp"),
+		Signature: header.Get("X-Signature"),
+	}
+}
+
+func isSigningHeader(k string) bool {
+	switch k {
+	case "X-Signature", "X-Nonce-Str", "X-Timestamp":
+		return true
+	}
+	return false
+}
+
+// requestBody returns the body in the form sent and signed, it's nil if there is no body.
+// GET request never carries a body, so `data=` is omitted from the signature,
+// and the signed string only consists of method, nonceStr, requestUrl, signType and timestamp.
+func (c *Client) requestBody(method string, b []byte) ([]byte, error) {
+	if method == "get" ||
+		len(b) == 0 ||
+		bytes.Equal(b, []byte(`null`)) ||
+		bytes.Equal(b, []byte(`{}`)) {
+		return nil, nil
+	}
+
+	if c.signRawBody {
+		buf := new(bytes.Buffer)
+		if err := json.Compact(buf, b); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	buf, err := canonicalJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SigningBaseString : returns the string to be signed for the request along with the
+// generated nonce and timestamp, it's useful to compare with RM when debugging the
+// signature mismatch. The body is normalized the same way as the request.
+func (c *Client) SigningBaseString(method, endpoint string, body []byte) (string, string, string) {
+	method = strings.TrimSpace(strings.ToLower(method))
+	b, err := c.requestBody(method, body)
+	if err != nil {
+		// not a valid JSON, sign it as it is
+		b = body
+	}
+
+	var b64Str string
+	if len(b) > 0 {
+		b64Str = base64.StdEncoding.EncodeToString(b)
+	}
+
+	nonce := c.nonce()
+	ts := strconv.FormatInt(c.now().Unix(), 10)
+	params := signParams(b64Str, method, endpoint, nonce, ts, signTypes[c.signType])
+	return strings.Join(params, "&"), nonce, ts
+}
+
+// readBody reads the response body, and decompresses it if RM responds with gzip.
+// `net/http` only decompresses transparently when it's the one requesting gzip,
+// which isn't the case for the custom transport or some gateways of RM.
+func readBody(res *http.Response) ([]byte, error) {
+	if !strings.EqualFold(strings.TrimSpace(res.Header.Get("Content-Encoding")), "gzip") {
+		return ioutil.ReadAll(res.Body)
+	}
+
+	r, err := gzip.NewReader(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// unmarshalJSON decodes with UseNumber, so the large numeric ids decoded into
+// interface{} won't lose precision by being converted to float64
+func unmarshalJSON(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// canonicalJSON sorts the keys of the json object and compacts it,
+// which is the form RM expects when computing the signature.
+// Numbers are kept as it is, so large integers won't lose precision.
+func canonicalJSON(b []byte) (*bytes.Buffer, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	// `encoding/json` sorts the keys of map
+	js, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(js), nil
+}
+
+// signParams returns the parameters of the signed string in sorted order,
+// `data` is omitted when there is no request body
+func signParams(b64Str, method, endpoint, nonceStr, timestamp, signType string) []string {
+	data := make([]string, 0, 6)
 	if b64Str != "" {
 		data = append(data, "data="+b64Str)
 	}
 	data = append(data, "method="+method)
-	data = append(data, "nonceStr="+randomStr)
+	data = append(data, "nonceStr="+nonceStr)
 	data = append(data, "requestUrl="+endpoint)
-	data = append(data, "signType=sha256")
-	data = append(data, "timestamp="+ts)
+	data = append(data, "signType="+signType)
+	data = append(data, "timestamp="+timestamp)
+	return data
+}
 
-	sign, err = signData(crypto.SHA256, data, c.pk)
+// verifyResponse verifies the `X-Signature` of the response using the public keys,
+// it's valid if any of the keys matches
+func (c *Client) verifyResponse(method, endpoint string, header http.Header, body []byte) error {
+	b64Str, err := encodeBody(body)
 	if err != nil {
 		return err
 	}
 
-	req.Header = http.Header{
-		"Accept":        {"application/json"},
-		"Content-Type":  {"application/json"},
-		"Authorization": {"Bearer " + tkn.AccessToken},
-		"X-Nonce-Str":   {randomStr},
-		"X-Signature":   {"sha256 " + sign},
-		"X-Timestamp":   {ts},
+	for _, key := range append([][]byte{c.pub}, c.pubs...) {
+		if len(key) == 0 {
+			continue
+		}
+
+		var pub *rsa.PublicKey
+		pub, err = parsePublicKey(key)
+		if err != nil {
+			return err
+		}
+
+		err = verifySignature(header, pub, func(signType string) []string {
+			return signParams(
+				b64Str,
+				method,
+				endpoint,
+				header.Get("X-Nonce-Str"),
+				header.Get("X-Timestamp"),
+				signType,
+			)
+		})
+		if err == nil {
+			return nil
+		}
 	}
+	return err
+}
 
-	var res *http.Response
-	res, err = http.DefaultClient.Do(req)
+// isSignatureMismatch reports whether the request is rejected by the signature,
+// the body is buffered so it can still be read after the check
+func isSignatureMismatch(res *http.Response) bool {
+	if res.StatusCode < http.StatusBadRequest || res.StatusCode >= http.StatusInternalServerError {
+		return false
+	}
+
+	b, err := readBody(res)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(b))
+	res.Header.Del("Content-Encoding")
 	if err != nil {
-		return err
+		return false
 	}
+	return gjson.GetBytes(b, "error.code").String() == ErrorCodeSignatureMismatch
+}
 
-	ext.HTTPStatusCode.Set(span, uint16(res.StatusCode))
+// encodeBody returns the base64 string of the canonical json body,
+// empty body will return an empty string
+func encodeBody(body []byte) (string, error) {
+	if len(body) == 0 ||
+		bytes.Equal(body, []byte(`null`)) ||
+		bytes.Equal(body, []byte(`{}`)) {
+		return "", nil
+	}
 
-	// skip to unmarshal if return status code is 204
-	if res.StatusCode == http.StatusNoContent {
-		return nil
+	buf, err := canonicalJSON(body)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// verifySignature verifies the `X-Signature` header against the signed data,
+// the hash algorithm is determined by the prefix of the signature
+func verifySignature(header http.Header, pub *rsa.PublicKey, params func(signType string) []string) error {
+	sign := strings.TrimSpace(header.Get("X-Signature"))
+	if sign == "" {
+		return ErrSignatureMismatch
 	}
 
-	respBytes, err := ioutil.ReadAll(res.Body)
+	hash, signType := crypto.SHA256, signTypes[crypto.SHA256]
+	if parts := strings.SplitN(sign, " ", 2); len(parts) == 2 {
+		h, ok := parseSignType(parts[0])
+		if !ok {
+			return ErrSignatureMismatch
+		}
+		hash, signType, sign = h, signTypes[h], parts[1]
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(sign)
 	if err != nil {
-		return err
+		return ErrSignatureMismatch
 	}
 
-	span.LogFields(
-		jlog.String("http.response.body", string(respBytes)),
-	)
+	h := hash.New()
+	h.Write([]byte(strings.Join(params(signType), "&")))
+	if err := rsa.VerifyPKCS1v15(pub, hash, h.Sum(nil), sig); err != nil {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
 
-	if res.StatusCode == http.StatusBadGateway {
-		return fmt.Errorf("rm: bad gateway on %s: %s", method, reqUrl.String())
+func parseSignType(signType string) (crypto.Hash, bool) {
+	for h, v := range signTypes {
+		if strings.EqualFold(v, signType) {
+			return h, true
+		}
 	}
+	return 0, false
+}
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		return newError(reqUrl.String(), b, respBytes)
+// parsePrivateKey supports both PKCS#1 (`RSA PRIVATE KEY`) and PKCS#8 (`PRIVATE KEY`)
+func parsePrivateKey(block *pem.Block) (*rsa.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		pk, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("rm: private key is not a rsa private key")
+		}
+		return pk, nil
+	default:
+		return nil, fmt.Errorf("rm: unsupported private key type %q", block.Type)
 	}
+}
 
-	err = json.Unmarshal(respBytes, dest)
+func parsePublicKey(b []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("rm: invalid format of public key")
+	}
+
+	// some keys are labelled as `RSA PUBLIC KEY` even though they're encoded in PKIX
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return err
+		if pub, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+			return pub, nil
+		}
+		return nil, err
 	}
-	return nil
+
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("rm: public key is not a rsa public key")
+	}
+	return pub, nil
+}
+
+// Sign : signs the data the same way as the client signs its requests, it returns the
+// base64 encoded signature and the base string, which is the key-value pairs sorted by
+// key and joined with `&`, e.g. "data=...&method=post&nonceStr=...".
+func Sign(hash crypto.Hash, pk *rsa.PrivateKey, data map[string]string) (string, string, error) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	params := make([]string, len(keys))
+	for i, k := range keys {
+		params[i] = k + "=" + data[k]
+	}
+
+	sign, err := signData(hash, params, pk)
+	if err != nil {
+		return "", "", err
+	}
+	return sign, strings.Join(params, "&"), nil
 }
 
 func signData(h crypto.Hash, data []string, pk *rsa.PrivateKey) (string, error) {
@@ -1,13 +1,28 @@
 package rm
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dchest/uniuri"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func mockRmClient() *Client {
@@ -31,12 +46,34 @@ func emptyRmClient() *Client {
 	pk, _ := ioutil.ReadFile("../test/pk.pem")
 	pub, _ := ioutil.ReadFile("../test/server_pub.pem")
 	return NewClient(Config{
-		PrivateKey: pk,
-		PublicKey:  pub,
-		StoreID:    "xxx",
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		PublicKey:    pub,
+		StoreID:      "xxx",
 	})
 }
 
+// mockServerClient returns a client which talks to the mock server
+// without requesting access token and verifying response signature
+func mockServerClient(srv *httptest.Server) *Client {
+	client := mockRmClient()
+	client.SetTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}))
+	client.openEndpoint = srv.URL
+	client.pub = nil
+	return client
+}
+
+// mockFileServer returns a mock server which always responds with the file content
+func mockFileServer(t *testing.T, f string) *httptest.Server {
+	b, err := ioutil.ReadFile(f)
+	require.NoError(t, err)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+}
+
 func TestRmClient(t *testing.T) {
 	ctx := context.Background()
 	client := mockRmClient()
@@ -45,7 +82,7 @@ func TestRmClient(t *testing.T) {
 	req.Order.ID = uniuri.NewLen(10)
 	req.Order.Title = "Testing #" + req.Order.ID
 	req.Order.Amount = 1000
-	req.Customer.UserID = "1234"
+	req.Customer = &CheckoutCustomer{UserID: "1234"}
 	req.StoreID = client.storeID
 	req.NotifyURL = "https://www.google.com"
 	req.RedirectURL = "https://www.google.com"
@@ -96,7 +133,7 @@ func TestRmClient(t *testing.T) {
 		req.Order.Title = "Payment Test"
 		req.CurrencyType = "MYR"
 		req.IsPreFillAmount = false
-		req.Method = make([]string, 0)
+		req.Method = make([]PaymentMethod, 0)
 		req.RedirectURL = "www.google.com"
 		req.Expiry.Type = "PERMANENT"
 		req.StoreID = client.storeID
@@ -113,3 +150,884 @@ func TestRmClient(t *testing.T) {
 		require.Nil(t, stores)
 	}
 }
+
+func TestVerifyResponse(t *testing.T) {
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	pub, _ := ioutil.ReadFile("../test/pub.pem")
+	client := NewClient(Config{
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		PublicKey:    pub,
+	})
+
+	var (
+		endpoint = "https://sb-open.revenuemonster.my/v3/stores"
+		body     = []byte(`{"items":[],"code":"SUCCESS"}`)
+		nonce    = uniuri.NewLen(25)
+		ts       = "1630000000"
+	)
+
+	buf, err := canonicalJSON(body)
+	require.NoError(t, err)
+
+	data := signParams(base64.StdEncoding.EncodeToString(buf.Bytes()), "get", endpoint, nonce, ts, "sha256")
+	sign, err := signData(crypto.SHA256, data, client.pk)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Nonce-Str", nonce)
+	header.Set("X-Timestamp", ts)
+	header.Set("X-Signature", "sha256 "+sign)
+	require.NoError(t, client.verifyResponse("get", endpoint, header, body))
+
+	// sha512 signature
+	data = signParams(base64.StdEncoding.EncodeToString(buf.Bytes()), "get", endpoint, nonce, ts, "sha512")
+	sign512, err := signData(crypto.SHA512, data, client.pk)
+	require.NoError(t, err)
+	header.Set("X-Signature", "sha512 "+sign512)
+	require.NoError(t, client.verifyResponse("get", endpoint, header, body))
+	header.Set("X-Signature", "sha256 "+sign)
+
+	// tampered response body
+	err = client.verifyResponse("get", endpoint, header, []byte(`{"items":[],"code":"FAILED"}`))
+	require.True(t, errors.Is(err, ErrSignatureMismatch))
+
+	// missing signature
+	header.Del("X-Signature")
+	err = client.verifyResponse("get", endpoint, header, body)
+	require.True(t, errors.Is(err, ErrSignatureMismatch))
+}
+
+func TestNewClientWithError(t *testing.T) {
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	pub, _ := ioutil.ReadFile("../test/pub.pem")
+
+	_, err := NewClientWithError(Config{PrivateKey: pk})
+	require.Error(t, err)
+
+	_, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: []byte("invalid")})
+	require.Error(t, err)
+
+	_, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: pub})
+	require.Error(t, err)
+
+	_, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: pk, SignType: crypto.MD5})
+	require.Error(t, err)
+
+	// all problems should be reported at once
+	_, err = NewClientWithError(Config{OpenEndpoint: "localhost", MaxRetries: -1})
+	var cfgErr ConfigError
+	require.ErrorAs(t, err, &cfgErr)
+	require.Len(t, cfgErr, 5)
+	require.True(t, strings.HasPrefix(err.Error(), "rm:"))
+
+	_, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: pk, Sandbox: true, OpenEndpoint: "https://open.revenuemonster.my"})
+	require.Error(t, err)
+
+	require.Panics(t, func() {
+		NewClient(Config{ClientID: "xxx"})
+	})
+
+	client, err := NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: pk})
+	require.NoError(t, err)
+	require.NotNil(t, client)
+
+	// endpoints override
+	client, err = NewClientWithError(Config{
+		ClientID:      "xxx",
+		ClientSecret:  "xxx",
+		PrivateKey:    pk,
+		Sandbox:       true,
+		OAuthEndpoint: "http://localhost:8080/",
+		OpenEndpoint:  "http://localhost:8081",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080", client.oauthEndpoint)
+	require.Equal(t, "http://localhost:8081", client.openEndpoint)
+
+	// load keys from path and reader
+	f, err := os.Open("../test/pk.pem")
+	require.NoError(t, err)
+	defer f.Close()
+	client, err = NewClientWithError(Config{
+		ClientID:         "xxx",
+		ClientSecret:     "xxx",
+		PrivateKeyReader: f,
+		PublicKeyPath:    "../test/pub.pem",
+	})
+	require.NoError(t, err)
+	require.Equal(t, pub, client.pub)
+
+	// the validation shouldn't drain the reader
+	f2, err := os.Open("../test/pk.pem")
+	require.NoError(t, err)
+	defer f2.Close()
+	cfg := Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKeyReader: f2}
+	require.NoError(t, cfg.Validate())
+	client, err = NewClientWithError(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, client.pk)
+
+	client, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKeyPath: "../test/pk.pem"})
+	require.NoError(t, err)
+
+	_, err = NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKeyPath: "../test/missing.pem"})
+	require.Error(t, err)
+
+	// PKCS#8 private key
+	pk8, _ := ioutil.ReadFile("../test/pk8.pem")
+	client8, err := NewClientWithError(Config{ClientID: "xxx", ClientSecret: "xxx", PrivateKey: pk8})
+	require.NoError(t, err)
+	require.Equal(t, client.pk, client8.pk)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	env := map[string]string{
+		EnvClientID:     "xxx",
+		EnvClientSecret: "xxx",
+		EnvPrivateKey:   base64.StdEncoding.EncodeToString(pk),
+		EnvStoreID:      "123",
+		EnvSandbox:      "true",
+	}
+	getenv := func(k string) string { return env[k] }
+
+	cfg, err := configFromEnv(getenv)
+	require.NoError(t, err)
+	require.Equal(t, pk, cfg.PrivateKey)
+	require.Equal(t, "123", cfg.StoreID)
+	require.True(t, cfg.Sandbox)
+
+	client, err := NewClientWithError(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "123", client.storeID)
+
+	// PEM with escaped newlines
+	env[EnvPrivateKey] = strings.ReplaceAll(string(pk), "\n", `\n`)
+	cfg, err = configFromEnv(getenv)
+	require.NoError(t, err)
+	require.Equal(t, pk, cfg.PrivateKey)
+
+	env[EnvPrivateKey] = base64.StdEncoding.EncodeToString([]byte("not a pem"))
+	_, err = configFromEnv(getenv)
+	require.Error(t, err)
+
+	env[EnvPrivateKey] = string(pk)
+	env[EnvSandbox] = "maybe"
+	_, err = configFromEnv(getenv)
+	require.Error(t, err)
+}
+
+func TestExtraHeaders(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	client := NewClient(Config{
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		TokenSource:  oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}),
+		OpenEndpoint: srv.URL,
+		ExtraHeaders: http.Header{
+			"x-partner-key": {"partner"},
+			"Authorization": {"Bearer gateway"},
+			"X-Signature":   {"fake"},
+		},
+	})
+
+	_, err := client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "partner", header.Get("X-Partner-Key"))
+	require.Equal(t, "Bearer xxx", header.Get("Authorization"))
+	require.Len(t, header.Values("X-Signature"), 1)
+	require.NotEqual(t, "fake", header.Get("X-Signature"))
+}
+
+func TestDryRun(t *testing.T) {
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	pub, err := parsePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry run request shouldn't be sent")
+	}))
+	defer srv.Close()
+
+	var hooked bool
+	client := mockServerClient(srv)
+	client.requestHook = func(*http.Request) { hooked = true }
+	client.dryRun = true
+
+	req := CreatePaymentCheckoutRequest{}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	req.Order.Amount = 1000
+	req.RedirectURL = "https://www.google.com"
+	req.NotifyURL = "https://www.google.com"
+	_, err = client.CreatePaymentCheckout(context.Background(), req)
+	require.True(t, errors.Is(err, ErrDryRun))
+	require.True(t, hooked)
+
+	var dryRun *DryRunError
+	require.True(t, errors.As(err, &dryRun))
+	require.Equal(t, http.MethodPost, dryRun.Request.Method)
+	require.Empty(t, dryRun.Request.Header.Get("Authorization"))
+	require.Contains(t, string(dryRun.Body), `"id":"1234"`)
+	require.NoError(t, verifySignature(dryRun.Request.Header, pub, func(signType string) []string {
+		return signParams(
+			base64.StdEncoding.EncodeToString(dryRun.Body),
+			"post",
+			dryRun.Request.URL.String(),
+			dryRun.Request.Header.Get("X-Nonce-Str"),
+			dryRun.Request.Header.Get("X-Timestamp"),
+			signType,
+		)
+	}))
+
+	// per request
+	client.dryRun = false
+	_, err = client.CreatePaymentCheckout(context.Background(), req, WithDryRun())
+	require.True(t, errors.As(err, &dryRun))
+	require.Equal(t, http.MethodPost, dryRun.Request.Method)
+}
+
+func TestEndpointURL(t *testing.T) {
+	client := emptyRmClient()
+	client.openEndpoint = "http://localhost:8080/"
+	client.oauthEndpoint = "http://localhost:8081"
+	require.Equal(t, "http://localhost:8080/v3/stores", client.openURL("/v3/stores"))
+	require.Equal(t, "http://localhost:8080/v3/stores", client.openURL("v3/stores"))
+	require.Equal(t, "http://localhost:8081/v1/token", client.oauthURL("/v1/token"))
+}
+
+func TestRequestSignature(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		client = mockServerClient(srv)
+		sig    RequestSignature
+		dest   json.RawMessage
+	)
+	require.NoError(t, client.do(context.Background(), "get_store", "GET", srv.URL+"/v3/stores/1", nil, &dest, WithRequestSignature(&sig)))
+	require.NotEmpty(t, sig.Nonce)
+	require.Equal(t, header.Get("X-Nonce-Str"), sig.Nonce)
+	require.Equal(t, header.Get("X-Timestamp"), sig.Timestamp)
+	require.Equal(t, header.Get("X-Signature"), sig.Signature)
+}
+
+func TestHTTPClient(t *testing.T) {
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	cfg := Config{
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		TokenSource:  oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}),
+	}
+
+	client := NewClient(cfg)
+	require.Equal(t, 30*time.Second, client.httpClient.Timeout)
+	require.Nil(t, client.httpClient.Transport)
+
+	// the injected client sends every request
+	var hits int32
+	cfg.HTTPClient = &http.Client{
+		Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&hits, 1)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"items":[],"code":"SUCCESS"}`)),
+				Request:    r,
+			}, nil
+		}),
+	}
+	client = NewClient(cfg)
+	require.Same(t, cfg.HTTPClient, client.httpClient)
+	require.Zero(t, client.httpClient.Timeout)
+	resp, err := client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ResponseSuccess, resp.Code)
+	require.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	cfg := Config{
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		TokenSource:  oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}),
+		OpenEndpoint: srv.URL,
+	}
+
+	// the certificate of the server isn't trusted by default
+	_, err := NewClient(cfg).GetStores(context.Background())
+	require.Error(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	cfg.TLSConfig = &tls.Config{RootCAs: pool}
+	_, err = NewClient(cfg).GetStores(context.Background())
+	require.NoError(t, err)
+
+	cfg.HTTPClient = http.DefaultClient
+	_, err = NewClientWithError(cfg)
+	require.Error(t, err)
+}
+
+func TestContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetStores(ctx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := client.GetStores(context.Background())
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// the shorter deadline of the caller wins
+	client.timeout = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = client.GetStores(ctx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestHooks(t *testing.T) {
+	srv := mockFileServer(t, "./sample/query_payment.json")
+	defer srv.Close()
+
+	var (
+		reqs  []*http.Request
+		resps [][]byte
+	)
+	client := mockServerClient(srv)
+	client.requestHook = func(r *http.Request) {
+		reqs = append(reqs, r)
+	}
+	client.responseHook = func(r *http.Response, b []byte) {
+		require.Equal(t, http.StatusOK, r.StatusCode)
+		resps = append(resps, b)
+	}
+
+	_, err := client.GetPaymentByOrderID(context.Background(), "128200910090623482313")
+	require.NoError(t, err)
+	require.Len(t, reqs, 1)
+	require.NotEmpty(t, reqs[0].Header.Get("X-Signature"))
+	require.Len(t, resps, 1)
+	require.Contains(t, string(resps[0]), "128200910090623482313")
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	buf, err := canonicalJSON([]byte(`{"z": "1", "a": {"c": 12345678901234567890, "b": 0.1}, "m": ["x", "<y>"]}`))
+	require.NoError(t, err)
+	// html characters are escaped the same way as `json.Marshal`
+	require.Equal(t, `{"a":{"b":0.1,"c":12345678901234567890},"m":["x","\u003cy\u003e"],"z":"1"}`, buf.String())
+
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.signRawBody = true
+	_, err = client.VoidTransaction(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, `{"transactionId":"1"}`, string(body))
+}
+
+type recordLogger struct {
+	debugs []string
+	errors []string
+}
+
+func (l *recordLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.debugs = append(l.debugs, fmt.Sprint(append([]interface{}{msg}, keysAndValues...)...))
+}
+
+func (l *recordLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprint(append([]interface{}{msg}, keysAndValues...)...))
+}
+
+func TestLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v3/stores/1" {
+			w.Write([]byte(`{"item":{"id":"1"},"code":"SUCCESS"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"STORE_NOT_FOUND"}}`))
+	}))
+	defer srv.Close()
+
+	logger := new(recordLogger)
+	client := mockServerClient(srv)
+	client.logger = logger
+
+	_, err := client.GetStore(context.Background(), "1")
+	require.NoError(t, err)
+	_, err = client.GetStore(context.Background(), "2")
+	require.Error(t, err)
+
+	require.Len(t, logger.debugs, 1)
+	require.Contains(t, logger.debugs[0], "get_store")
+	require.Len(t, logger.errors, 1)
+	require.Contains(t, logger.errors[0], "STORE_NOT_FOUND")
+	require.Contains(t, logger.errors[0], "404")
+}
+
+type observation struct {
+	operation  string
+	statusCode int
+	err        error
+}
+
+type recordMetrics []observation
+
+func (m *recordMetrics) ObserveRequest(operation string, statusCode int, duration time.Duration, err error) {
+	*m = append(*m, observation{operation, statusCode, err})
+}
+
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v3/stores/1" {
+			w.Write([]byte(`{"item":{"id":"1"},"code":"SUCCESS"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"STORE_NOT_FOUND"}}`))
+	}))
+	defer srv.Close()
+
+	metrics := new(recordMetrics)
+	client := mockServerClient(srv)
+	client.metrics = metrics
+
+	client.GetStore(context.Background(), "1")
+	client.GetStore(context.Background(), "2")
+
+	require.Len(t, *metrics, 2)
+	require.Equal(t, observation{"get_store", http.StatusOK, nil}, (*metrics)[0])
+	require.Equal(t, "get_store", (*metrics)[1].operation)
+	require.Equal(t, http.StatusNotFound, (*metrics)[1].statusCode)
+	require.ErrorIs(t, (*metrics)[1].err, ErrStoreNotFound)
+}
+
+func TestOperationName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"checkoutId":"1234"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	metrics := new(recordMetrics)
+	client := mockServerClient(srv)
+	client.metrics = metrics
+
+	req := CreatePaymentCheckoutRequest{}
+	req.Order.ID = "1234"
+	req.Order.Title = "Testing"
+	req.Order.Amount = 1000
+	req.RedirectURL = "https://www.google.com"
+	req.NotifyURL = "https://www.google.com"
+
+	_, err := client.CreatePaymentCheckout(context.Background(), req, WithOperationName("create_payment_checkout_fpx"))
+	require.NoError(t, err)
+
+	ctx := ContextWithOperationName(context.Background(), func(op string) string { return op + "_web" })
+	_, err = client.CreatePaymentCheckout(ctx, req)
+	require.NoError(t, err)
+
+	require.Len(t, *metrics, 2)
+	require.Equal(t, "create_payment_checkout_fpx", (*metrics)[0].operation)
+	require.Equal(t, "create_payment_checkout_web", (*metrics)[1].operation)
+}
+
+func TestSignParams(t *testing.T) {
+	endpoint := "https://sb-open.revenuemonster.my/v3/stores?limit=10"
+	require.Equal(t, []string{
+		"method=get",
+		"nonceStr=nonce",
+		"requestUrl=" + endpoint,
+		"signType=sha256",
+		"timestamp=1630000000",
+	}, signParams("", "get", endpoint, "nonce", "1630000000", "sha256"))
+
+	require.Equal(t, []string{
+		"data=e30=",
+		"method=post",
+		"nonceStr=nonce",
+		"requestUrl=" + endpoint,
+		"signType=sha256",
+		"timestamp=1630000000",
+	}, signParams("e30=", "post", endpoint, "nonce", "1630000000", "sha256"))
+}
+
+func TestGetRequestSignature(t *testing.T) {
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	pub, err := parsePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		require.Empty(t, b)
+
+		endpoint := "http://" + r.Host + r.URL.RequestURI()
+		require.NoError(t, verifySignature(r.Header, pub, func(signType string) []string {
+			return []string{
+				"method=get",
+				"nonceStr=" + r.Header.Get("X-Nonce-Str"),
+				"requestUrl=" + endpoint,
+				"signType=" + signType,
+				"timestamp=" + r.Header.Get("X-Timestamp"),
+			}
+		}))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	// the body of GET request is neither sent nor signed
+	resp := new(GetStoresResponse)
+	require.NoError(t, client.do(context.Background(), "get_stores", "GET", srv.URL+"/v3/stores?limit=10", map[string]string{"a": "b"}, resp))
+	require.Equal(t, ResponseSuccess, resp.Code)
+}
+
+func TestDeleteRequestSignature(t *testing.T) {
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	pub, err := parsePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("unexpected method %q", r.Method)
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		endpoint := "http://" + r.Host + r.URL.RequestURI()
+		if err := verifySignature(r.Header, pub, func(signType string) []string {
+			return signParams(
+				base64.StdEncoding.EncodeToString(b),
+				"delete",
+				endpoint,
+				r.Header.Get("X-Nonce-Str"),
+				r.Header.Get("X-Timestamp"),
+				signType,
+			)
+		}); err != nil {
+			t.Errorf("invalid signature: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		client = mockServerClient(srv)
+		resp   map[string]interface{}
+	)
+	// without body, `data=` is omitted from the signature
+	require.NoError(t, client.do(context.Background(), "delete_store", "DELETE", srv.URL+"/v3/store/123", nil, &resp))
+	require.NoError(t, client.do(context.Background(), "delete_store", "delete", srv.URL+"/v3/store/123", map[string]string{}, &resp))
+	// with body
+	require.NoError(t, client.do(context.Background(), "void_voucher", "DELETE", srv.URL+"/v3/voucher/123", map[string]string{"reason": "test"}, &resp))
+	require.Equal(t, "SUCCESS", resp["code"])
+}
+
+func TestDeterministicSignature(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	client := NewClient(Config{
+		ClientID:     "xxx",
+		ClientSecret: "xxx",
+		PrivateKey:   pk,
+		TokenSource:  oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}),
+		OpenEndpoint: srv.URL,
+		NonceFunc:    func() string { return "fixed-nonce" },
+		TimeFunc:     func() time.Time { return time.Unix(1630000000, 0) },
+	})
+
+	_, err := client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "fixed-nonce", header.Get("X-Nonce-Str"))
+	require.Equal(t, "1630000000", header.Get("X-Timestamp"))
+
+	sign, err := signData(crypto.SHA256, []string{
+		"method=get",
+		"nonceStr=fixed-nonce",
+		"requestUrl=" + srv.URL + "/v3/stores?limit=100",
+		"signType=sha256",
+		"timestamp=1630000000",
+	}, client.pk)
+	require.NoError(t, err)
+	require.Equal(t, "sha256 "+sign, header.Get("X-Signature"))
+}
+
+func TestGzipResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"items":[{"id":"1","name":"Store 1"}],"code":"SUCCESS"}`))
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.httpClient = &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	resp, err := client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	require.Equal(t, "Store 1", resp.Items[0].Name)
+}
+
+func TestSign(t *testing.T) {
+	client := emptyRmClient()
+	endpoint := "https://sb-open.revenuemonster.my/v3/stores"
+	sign, base, err := Sign(crypto.SHA256, client.pk, map[string]string{
+		"timestamp":  "1630000000",
+		"signType":   "sha256",
+		"requestUrl": endpoint,
+		"nonceStr":   "nonce",
+		"method":     "get",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "method=get&nonceStr=nonce&requestUrl="+endpoint+"&signType=sha256&timestamp=1630000000", base)
+
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	pub, err := parsePublicKey(pubPEM)
+	require.NoError(t, err)
+	header := http.Header{}
+	header.Set("X-Signature", "sha256 "+sign)
+	require.NoError(t, verifySignature(header, pub, func(signType string) []string {
+		return signParams("", "get", endpoint, "nonce", "1630000000", signType)
+	}))
+}
+
+type correlationKey struct{}
+
+func TestCorrelationHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"` + r.Header.Get("X-Correlation-Id") + `"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	client.ctxHeader = func(ctx context.Context) (string, string) {
+		id, _ := ctx.Value(correlationKey{}).(string)
+		return "X-Correlation-Id", id
+	}
+
+	ctx := context.WithValue(context.Background(), correlationKey{}, "correlation-id")
+	resp, err := client.GetStores(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "correlation-id", resp.Code)
+
+	resp, err = client.GetStores(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, resp.Code)
+
+	// the context header never overrides the headers set by the client
+	for _, k := range []string{"authorization", "Idempotency-Key", "X-Signature"} {
+		k := k
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NotEqual(t, "overridden", r.Header.Get(k))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"item":{},"code":"SUCCESS"}`))
+		}))
+		client := mockServerClient(srv)
+		client.ctxHeader = func(ctx context.Context) (string, string) {
+			return k, "overridden"
+		}
+		_, err = client.CreateDynamicQR(context.Background(), CreateQRRequest{Amount: 100}, WithIdempotencyKey("key"))
+		require.NoError(t, err)
+		srv.Close()
+	}
+}
+
+func TestLargeNumber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"transactionId":200910090708300425661809,"storeId":2808912573238362402},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	resp := make(map[string]interface{})
+	require.NoError(t, client.do(context.Background(), "get_transaction", "get", srv.URL, nil, &resp))
+	item := resp["item"].(map[string]interface{})
+	require.Equal(t, json.Number("200910090708300425661809"), item["transactionId"])
+	require.Equal(t, json.Number("2808912573238362402"), item["storeId"])
+
+	item = make(map[string]interface{})
+	_, err := client.doUnwrap(context.Background(), "get_transaction", "get", srv.URL, nil, &item)
+	require.NoError(t, err)
+	require.Equal(t, json.Number("2808912573238362402"), item["storeId"])
+}
+
+func TestSigningBaseString(t *testing.T) {
+	client := emptyRmClient()
+	client.nonce = func() string { return "nonce" }
+	client.now = func() time.Time { return time.Unix(1630000000, 0) }
+
+	endpoint := "https://sb-open.revenuemonster.my/v3/payment/reverse"
+	base, nonce, ts := client.SigningBaseString("POST", endpoint, []byte(`{"transactionId":"1","reason":"void"}`))
+	require.Equal(t, "nonce", nonce)
+	require.Equal(t, "1630000000", ts)
+	// the body is signed with the sorted keys
+	b64 := base64.StdEncoding.EncodeToString([]byte(`{"reason":"void","transactionId":"1"}`))
+	require.Equal(t, "data="+b64+"&method=post&nonceStr=nonce&requestUrl="+endpoint+"&signType=sha256&timestamp=1630000000", base)
+
+	base, _, _ = client.SigningBaseString("GET", endpoint, nil)
+	require.Equal(t, "method=get&nonceStr=nonce&requestUrl="+endpoint+"&signType=sha256&timestamp=1630000000", base)
+}
+
+func TestRawResponse(t *testing.T) {
+	body := "{\n  \"item\": {\"code\": \"qr-code\", \"amount\": 500},\n  \"code\": \"SUCCESS\"\n}\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+
+	var raw []byte
+	qr, err := client.CreateDynamicQR(context.Background(), CreateQRRequest{Amount: 500}, WithRawResponse(&raw))
+	require.NoError(t, err)
+	require.Equal(t, "qr-code", qr.Code)
+	require.Equal(t, body, string(raw))
+
+	raw, err = client.DoRaw(context.Background(), "GET", "/v3/payment/transaction/qrcode/qr-code", nil)
+	require.NoError(t, err)
+	require.Equal(t, body, string(raw))
+
+	// the unsigned body is never handed over
+	client.pub, _ = ioutil.ReadFile("../test/pub.pem")
+	raw = nil
+	_, err = client.CreateDynamicQR(context.Background(), CreateQRRequest{Amount: 500}, WithRawResponse(&raw))
+	require.ErrorIs(t, err, ErrSignatureMismatch)
+	require.Nil(t, raw)
+}
+
+func TestDecodeResponse(t *testing.T) {
+	var qr QRResponse
+	page, err := DecodeResponse([]byte(`{"item":{"code":"qr-code","amount":500},"code":"SUCCESS"}`), &qr)
+	require.NoError(t, err)
+	require.Equal(t, "qr-code", qr.Code)
+	require.Equal(t, Amount(500), qr.Amount)
+	require.Zero(t, page)
+
+	var stores []Store
+	page, err = DecodeResponse([]byte(`{"items":[{"id":"1"},{"id":"2"}],"meta":{"count":2,"total":5},"code":"SUCCESS"}`), &stores)
+	require.NoError(t, err)
+	require.Len(t, stores, 2)
+	require.Equal(t, 5, page.Total)
+
+	_, err = DecodeResponse([]byte(`not json`), &stores)
+	require.Error(t, err)
+}
+
+func TestContentType(t *testing.T) {
+	pubPEM, _ := ioutil.ReadFile("../test/pub.pem")
+	pub, err := parsePublicKey(pubPEM)
+	require.NoError(t, err)
+
+	img := []byte("\x89PNG\r\n\x1a\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		endpoint := "http://" + r.Host + r.URL.RequestURI()
+		require.NoError(t, verifySignature(r.Header, pub, func(signType string) []string {
+			return signParams(base64.StdEncoding.EncodeToString(b), "post", endpoint, r.Header.Get("X-Nonce-Str"), r.Header.Get("X-Timestamp"), signType)
+		}))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"` + r.Header.Get("Content-Type") + `","item":"` + base64.StdEncoding.EncodeToString(b) + `"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+		resp   struct {
+			Code string `json:"code"`
+			Item string `json:"item"`
+		}
+	)
+	require.NoError(t, client.do(ctx, "upload", "post", srv.URL+"/upload", bytes.NewReader(img), &resp, WithContentType("image/png")))
+	require.Equal(t, "image/png", resp.Code)
+	require.Equal(t, base64.StdEncoding.EncodeToString(img), resp.Item)
+
+	// json is still the default
+	require.NoError(t, client.do(ctx, "post", "post", srv.URL+"/post", map[string]string{"b": "1", "a": "2"}, &resp))
+	require.Equal(t, "application/json", resp.Code)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"a":"2","b":"1"}`)), resp.Item)
+
+	// the body other than json must be bytes
+	err = client.do(ctx, "upload", "post", srv.URL+"/upload", map[string]string{}, &resp, WithContentType("image/png"))
+	require.Error(t, err)
+}
@@ -0,0 +1,100 @@
+// Package rmtest provides the helpers to test the code using the rm client
+// without hitting the RM sandbox.
+package rmtest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	rm "github.com/si3nloong/rm-go-client/v3"
+	"golang.org/x/oauth2"
+)
+
+// TestResponse : the stubbed response of the endpoint
+type TestResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// NewTestClient : returns a client which responds with the stubbed responses.
+// The key of responses is either "METHOD /path" or "/path", e.g. "GET /v3/stores".
+// Any request without stubbed response gets 404 with the `NOT_FOUND` error code.
+//
+// The client doesn't request access token and skips the signature verification.
+// The client id, client secret and private key are filled in if they are empty.
+func NewTestClient(cfg rm.Config, responses map[string]TestResponse) *rm.Client {
+	if cfg.ClientID == "" {
+		cfg.ClientID = "rmtest"
+	}
+	if cfg.ClientSecret == "" {
+		cfg.ClientSecret = "rmtest"
+	}
+	if len(cfg.PrivateKey) == 0 {
+		cfg.PrivateKey = generatePrivateKey()
+	}
+	cfg.PublicKey = nil
+	cfg.PublicKeys = nil
+	cfg.PublicKeyPath = ""
+	cfg.TokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "rmtest"})
+	cfg.HTTPClient = &http.Client{
+		Transport: rm.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			resp, ok := responses[strings.ToUpper(r.Method)+" "+r.URL.Path]
+			if !ok {
+				resp, ok = responses[r.URL.Path]
+			}
+			if !ok {
+				resp = TestResponse{
+					StatusCode: http.StatusNotFound,
+					Body:       []byte(`{"error":{"code":"NOT_FOUND","message":"no stubbed response"}}`),
+				}
+			}
+			return resp.response(r), nil
+		}),
+	}
+	return rm.NewClient(cfg)
+}
+
+func (tr TestResponse) response(r *http.Request) *http.Response {
+	statusCode := tr.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	header := http.Header{}
+	for k, v := range tr.Header {
+		header[k] = v
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json")
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(tr.Body)),
+		ContentLength: int64(len(tr.Body)),
+		Request:       r,
+	}
+}
+
+func generatePrivateKey() []byte {
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(pk),
+	})
+}
@@ -0,0 +1,53 @@
+package rmtest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	rm "github.com/si3nloong/rm-go-client/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestClient(t *testing.T) {
+	ctx := context.Background()
+	client := NewTestClient(rm.Config{StoreID: "1"}, map[string]TestResponse{
+		"GET /v3/stores/1": {
+			Body: []byte(`{"item":{"id":"1","name":"Store 1"},"code":"SUCCESS"}`),
+		},
+		"/v3/merchant": {
+			Body: []byte(`{"item":{"id":"1","companyName":"RM"},"code":"SUCCESS"}`),
+		},
+	})
+
+	store, err := client.GetStore(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "Store 1", store.Name)
+
+	require.NoError(t, client.Ping(ctx))
+
+	_, err = client.GetStore(ctx, "2")
+	var apiErr *rm.APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, "NOT_FOUND", apiErr.Code)
+}
+
+func TestNewTestClientWithPublicKeys(t *testing.T) {
+	pub, err := ioutil.ReadFile("../../test/pub.pem")
+	require.NoError(t, err)
+
+	// the stubbed responses aren't signed, so the public keys must be ignored
+	client := NewTestClient(rm.Config{
+		PublicKeys:    [][]byte{pub},
+		PublicKeyPath: "../../test/pub.pem",
+	}, map[string]TestResponse{
+		"/v3/stores/1": {
+			Body: []byte(`{"item":{"id":"1","name":"Store 1"},"code":"SUCCESS"}`),
+		},
+	})
+
+	store, err := client.GetStore(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, "Store 1", store.Name)
+}
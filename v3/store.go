@@ -2,6 +2,10 @@ package rm
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -9,6 +13,7 @@ import (
 type Store struct {
 	ID           string `json:"id"`
 	Name         string `json:"name"`
+	ImageURL     string `json:"imageUrl"`
 	AddressLine1 string `json:"addressLine1"`
 	AddressLine2 string `json:"addressLine2"`
 	PostCode     string `json:"postCode"`
@@ -43,7 +48,7 @@ func (c *Client) GetStores(ctx context.Context) (*GetStoresResponse, error) {
 		ctx,
 		"get_stores",
 		"get",
-		c.openEndpoint+"/v3/stores?limit=100",
+		c.openURL("/v3/stores?limit=100"),
 		nil,
 		resp,
 	); err != nil {
@@ -51,3 +56,155 @@ func (c *Client) GetStores(ctx context.Context) (*GetStoresResponse, error) {
 	}
 	return resp, nil
 }
+
+// GetStore :
+func (c *Client) GetStore(ctx context.Context, storeID string) (*Store, error) {
+	item := new(Store)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_store",
+		"get",
+		c.openURL("/v3/stores/"+url.PathEscape(storeID)),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateStoreRequest :
+type CreateStoreRequest struct {
+	Name         string `json:"name"`
+	AddressLine1 string `json:"addressLine1"`
+	AddressLine2 string `json:"addressLine2,omitempty"`
+	PostCode     string `json:"postCode"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Country      string `json:"country"`
+	CountryCode  string `json:"countryCode"`
+	PhoneNumber  string `json:"phoneNumber"`
+	// ExternalReference identifies the store in your system, it's only sent as the
+	// `Idempotency-Key` header and never stored by RM
+	ExternalReference string `json:"-"`
+}
+
+// CreateStore : the ExternalReference is sent as the idempotency key, overriding
+// WithIdempotencyKey, and the same key is reused by every retry of the call.
+// The deduplication of the re-run creation depends on RM honouring the key,
+// the client doesn't look up the existing store by the reference.
+func (c *Client) CreateStore(
+	ctx context.Context,
+	req CreateStoreRequest,
+	opts ...RequestOption,
+) (*Store, error) {
+	if req.ExternalReference != "" {
+		opts = append(opts, WithIdempotencyKey(req.ExternalReference))
+	}
+
+	item := new(Store)
+	if _, err := c.doUnwrap(
+		ctx,
+		"create_store",
+		"post",
+		c.openURL("/v3/stores"),
+		req,
+		item,
+		append(opts, idempotent())...,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ListStoreOptions :
+type ListStoreOptions struct {
+	Offset int
+	// Limit is the number of stores per page, default to 100
+	Limit int
+}
+
+// ListStores : returns a page of the stores
+func (c *Client) ListStores(ctx context.Context, opts ListStoreOptions) ([]Store, Pagination, error) {
+	items := make([]Store, 0)
+	page, err := c.list(ctx, "list_stores", c.openURL("/v3/stores"), nil, opts.Offset, opts.Limit, &items)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return items, page, nil
+}
+
+// store status :
+const (
+	StoreStatusActive   = "ACTIVE"
+	StoreStatusInactive = "INACTIVE"
+)
+
+// StoreUpdate : the fields to update, nil means unchanged
+type StoreUpdate struct {
+	Name         *string `json:"name,omitempty"`
+	PhoneNumber  *string `json:"phoneNumber,omitempty"`
+	CountryCode  *string `json:"countryCode,omitempty"`
+	AddressLine1 *string `json:"addressLine1,omitempty"`
+	AddressLine2 *string `json:"addressLine2,omitempty"`
+	PostCode     *string `json:"postCode,omitempty"`
+	City         *string `json:"city,omitempty"`
+	State        *string `json:"state,omitempty"`
+	Country      *string `json:"country,omitempty"`
+	Status       *string `json:"status,omitempty"`
+}
+
+// UpdateStore :
+func (c *Client) UpdateStore(ctx context.Context, storeID string, patch StoreUpdate) (*Store, error) {
+	item := new(Store)
+	if _, err := c.doUnwrap(
+		ctx,
+		"update_store",
+		"patch",
+		c.openURL("/v3/stores/"+url.PathEscape(storeID)),
+		patch,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ActivateStore :
+func (c *Client) ActivateStore(ctx context.Context, storeID string) (*Store, error) {
+	status := StoreStatusActive
+	return c.UpdateStore(ctx, storeID, StoreUpdate{Status: &status})
+}
+
+// DeactivateStore : pauses the store, e.g. during holidays
+func (c *Client) DeactivateStore(ctx context.Context, storeID string) (*Store, error) {
+	status := StoreStatusInactive
+	return c.UpdateStore(ctx, storeID, StoreUpdate{Status: &status})
+}
+
+// UploadResult :
+type UploadResult struct {
+	URL string `json:"url"`
+}
+
+// UploadStoreLogo : uploads the logo of the store displayed on the checkout page,
+// contentType is the mime type of the image, e.g. "image/png"
+func (c *Client) UploadStoreLogo(ctx context.Context, storeID string, img io.Reader, contentType string) (*UploadResult, error) {
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("rm: invalid content type %q of the image", contentType)
+	}
+
+	item := new(UploadResult)
+	if _, err := c.doUnwrap(
+		ctx,
+		"upload_store_logo",
+		"post",
+		c.openURL("/v3/stores/"+url.PathEscape(storeID)+"/logo"),
+		img,
+		item,
+		WithContentType(contentType),
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
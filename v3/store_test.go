@@ -0,0 +1,161 @@
+package rm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, defaultUserAgent, r.UserAgent())
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.EscapedPath() == "/v3/stores/a%2Fb" {
+			w.Write([]byte(`{"item":{"id":"a/b","name":"Store A"},"code":"SUCCESS"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v3/stores":
+			require.Equal(t, "10", r.URL.Query().Get("offset"))
+			require.Equal(t, "5", r.URL.Query().Get("limit"))
+			w.Write([]byte(`{"items":[{"id":"1","name":"Store 1"}],"code":"SUCCESS","meta":{"count":1,"total":11}}`))
+		case "/v3/stores/1":
+			w.Write([]byte(`{"item":{"id":"1","name":"Store 1","geoLocation":{"latitude":3.13,"longitude":101.61},"status":"ACTIVE"},"code":"SUCCESS"}`))
+		default:
+			w.Header().Set("X-Request-Id", "request-id")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"STORE_NOT_FOUND","message":"Store not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+
+	stores, page, err := client.ListStores(ctx, ListStoreOptions{Offset: 10, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, stores, 1)
+	require.Equal(t, Pagination{Offset: 10, Limit: 5, Total: 11}, page)
+
+	store, err := client.GetStore(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, "Store 1", store.Name)
+	require.Equal(t, 3.13, store.GeoLocation.Latitude)
+	require.Equal(t, "ACTIVE", store.Status)
+
+	store, err = client.GetStore(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, "Store A", store.Name)
+
+	_, err = client.GetStore(ctx, "2")
+	require.ErrorIs(t, err, ErrStoreNotFound)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "request-id", apiErr.RequestID)
+	require.Contains(t, fmt.Sprintf("%+v", apiErr), "request-id")
+}
+
+func TestUpdateStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, "/v3/stores/1", r.URL.Path)
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch string(b) {
+		case `{"status":"INACTIVE"}`:
+			w.Write([]byte(`{"item":{"id":"1","name":"Store 1","status":"INACTIVE"},"code":"SUCCESS"}`))
+		case `{"name":"Store 2","phoneNumber":"0123456789"}`:
+			w.Write([]byte(`{"item":{"id":"1","name":"Store 2","phoneNumber":"0123456789","status":"ACTIVE"},"code":"SUCCESS"}`))
+		default:
+			t.Errorf("unexpected body %s", b)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := mockServerClient(srv)
+
+	store, err := client.DeactivateStore(ctx, "1")
+	require.NoError(t, err)
+	require.Equal(t, StoreStatusInactive, store.Status)
+
+	name, phone := "Store 2", "0123456789"
+	store, err = client.UpdateStore(ctx, "1", StoreUpdate{Name: &name, PhoneNumber: &phone})
+	require.NoError(t, err)
+	require.Equal(t, "Store 2", store.Name)
+}
+
+func TestUploadStoreLogo(t *testing.T) {
+	img := []byte("\x89PNG\r\n\x1a\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/stores/1/logo", r.URL.Path)
+		require.Equal(t, "image/png", r.Header.Get("Content-Type"))
+		b, _ := ioutil.ReadAll(r.Body)
+		require.Equal(t, img, b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"url":"https://cdn.example.com/logo.png"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	client := mockServerClient(srv)
+	res, err := client.UploadStoreLogo(context.Background(), "1", bytes.NewReader(img), "image/png")
+	require.NoError(t, err)
+	require.Equal(t, "https://cdn.example.com/logo.png", res.URL)
+
+	_, err = client.UploadStoreLogo(context.Background(), "1", bytes.NewReader(img), "application/json")
+	require.Error(t, err)
+}
+
+func TestCreateStore(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		require.NotContains(t, string(body), "outlet-1")
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		// the first attempt fails, so the creation is retried
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"id":"1","name":"Outlet"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+		req    = CreateStoreRequest{Name: "Outlet", ExternalReference: "outlet-1"}
+	)
+	client.maxRetries = 1
+	client.retryBackoff = time.Millisecond
+
+	store, err := client.CreateStore(ctx, req, WithIdempotencyKey("ignored"))
+	require.NoError(t, err)
+	require.Equal(t, "1", store.ID)
+	// every attempt carries the reference as the idempotency key
+	require.Equal(t, []string{"outlet-1", "outlet-1"}, keys)
+
+	keys = nil
+	client.maxRetries = 0
+	_, err = client.CreateStore(ctx, CreateStoreRequest{Name: "Outlet"})
+	require.Error(t, err)
+	require.Equal(t, []string{""}, keys)
+}
@@ -0,0 +1,51 @@
+package rm
+
+import (
+	"context"
+	"time"
+)
+
+// DailySummary : the aggregate of the successful transactions of the store in a day
+type DailySummary struct {
+	StoreID     string
+	Date        time.Time
+	Count       int
+	GrossAmount Amount
+	Fee         Amount
+	NetAmount   Amount
+}
+
+// GetDailySummary : returns the summary of the successful transactions of the store
+// on the date, the day is bounded by the location of date, e.g. MYT. RM doesn't offer
+// the summary endpoint, so it's derived from the transactions of the day.
+func (c *Client) GetDailySummary(
+	ctx context.Context,
+	storeID string,
+	date time.Time,
+) (*DailySummary, error) {
+	y, m, d := date.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+
+	txs, err := c.ListAllTransactions(ctx, ListTransactionsOptions{
+		StoreID: storeID,
+		From:    start,
+		To:      start.AddDate(0, 0, 1),
+		Status:  PaymentStatusSuccess,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &DailySummary{StoreID: storeID, Date: start}
+	for _, tx := range txs {
+		// the status filter is applied by RM, it's checked again in case it's ignored
+		if tx.Status != PaymentStatusSuccess {
+			continue
+		}
+		summary.Count++
+		summary.GrossAmount += tx.Order.Amount
+		summary.Fee += tx.Fee()
+		summary.NetAmount += tx.NetAmount
+	}
+	return summary, nil
+}
@@ -0,0 +1,42 @@
+package rm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDailySummary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("storeId") != "store-1" ||
+			q.Get("startAt") != "2021-02-28T16:00:00Z" ||
+			q.Get("endAt") != "2021-03-01T16:00:00Z" ||
+			q.Get("status") != "SUCCESS" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[
+			{"transactionId":"1","order":{"amount":1000},"status":"SUCCESS","platformCharge":10,"mdrCharge":5,"netAmount":985},
+			{"transactionId":"2","order":{"amount":2000},"status":"SUCCESS","platformCharge":20,"mdrCharge":10,"netAmount":1970},
+			{"transactionId":"3","order":{"amount":500},"status":"FAILED"}
+		],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	myt := time.FixedZone("MYT", 8*60*60)
+	summary, err := mockServerClient(srv).GetDailySummary(context.Background(), "store-1", time.Date(2021, 3, 1, 15, 4, 5, 0, myt))
+	require.NoError(t, err)
+	require.Equal(t, &DailySummary{
+		StoreID:     "store-1",
+		Date:        time.Date(2021, 3, 1, 0, 0, 0, 0, myt),
+		Count:       2,
+		GrossAmount: 3000,
+		Fee:         45,
+		NetAmount:   2955,
+	}, summary)
+}
@@ -0,0 +1,66 @@
+package rm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Time : the timestamp returned by RM, which accepts RFC3339 and
+// unix timestamp in seconds or milliseconds (either number or string).
+// It's always in UTC.
+type Time struct {
+	time.Time
+}
+
+var (
+	_ json.Unmarshaler = (*Time)(nil)
+	_ json.Marshaler   = (*Time)(nil)
+)
+
+// unix timestamp greater than this is considered as milliseconds,
+// which is year 5138 in seconds
+const maxUnixSeconds = 1e11
+
+// UnmarshalJSON :
+func (t *Time) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte(`null`)) {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	s := string(b)
+	if b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			t.Time = time.Time{}
+			return nil
+		}
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > maxUnixSeconds || n < -maxUnixSeconds {
+			t.Time = time.Unix(0, n*int64(time.Millisecond)).UTC()
+		} else {
+			t.Time = time.Unix(n, 0).UTC()
+		}
+		return nil
+	}
+
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("rm: invalid time %q", s)
+	}
+	t.Time = v.UTC()
+	return nil
+}
+
+// MarshalJSON :
+func (t Time) MarshalJSON() ([]byte, error) {
+	return t.Time.MarshalJSON()
+}
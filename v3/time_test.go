@@ -0,0 +1,39 @@
+package rm
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTime(t *testing.T) {
+	want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	for _, v := range []string{
+		`"2021-03-04T13:06:07+08:00"`,
+		`"2021-03-04T05:06:07Z"`,
+		`1614834367`,
+		`"1614834367"`,
+		`1614834367000`,
+	} {
+		var i struct {
+			CreatedAt Time `json:"createdAt"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(`{"createdAt":`+v+`}`), &i), v)
+		require.True(t, want.Equal(i.CreatedAt.Time), v)
+		require.Equal(t, time.UTC, i.CreatedAt.Location())
+	}
+
+	var tm Time
+	require.NoError(t, json.Unmarshal([]byte(`null`), &tm))
+	require.True(t, tm.IsZero())
+	require.NoError(t, json.Unmarshal([]byte(`""`), &tm))
+	require.True(t, tm.IsZero())
+	require.Error(t, json.Unmarshal([]byte(`"yesterday"`), &tm))
+
+	b, err := json.Marshal(Time{want})
+	require.NoError(t, err)
+	require.Equal(t, `"2021-03-04T05:06:07Z"`, string(b))
+}
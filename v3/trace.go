@@ -0,0 +1,91 @@
+package rm
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	jlog "github.com/opentracing/opentracing-go/log"
+)
+
+// HTTPTrace : the timing of each phase of the request, the phases skipped are zero,
+// e.g. DNSLookup, Connect and TLSHandshake if the connection is reused
+type HTTPTrace struct {
+	DNSLookup       time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	ConnReused      bool
+}
+
+// HTTPTraceObserver : the MetricsObserver may implement it to receive the timing of
+// every attempt when Config.EnableHTTPTrace is set
+type HTTPTraceObserver interface {
+	ObserveHTTPTrace(operation string, trace HTTPTrace)
+}
+
+type httpTracer struct {
+	mu                                   sync.Mutex
+	trace                                HTTPTrace
+	start, dnsStart, connStart, tlsStart time.Time
+}
+
+func (t *httpTracer) clientTrace() *httptrace.ClientTrace {
+	// the callbacks might be invoked concurrently, e.g. dialing multiple addresses
+	record := func(fn func()) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		fn()
+	}
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			record(func() { t.start = time.Now() })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func() { t.trace.ConnReused = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			record(func() { t.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func() { t.trace.DNSLookup = time.Since(t.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			record(func() { t.connStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			record(func() { t.trace.Connect = time.Since(t.connStart) })
+		},
+		TLSHandshakeStart: func() {
+			record(func() { t.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() { t.trace.TLSHandshake = time.Since(t.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { t.trace.TimeToFirstByte = time.Since(t.start) })
+		},
+	}
+}
+
+func (t *httpTracer) result() HTTPTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.trace
+}
+
+func (c *Client) observeHTTPTrace(span opentracing.Span, operationName string, trace HTTPTrace) {
+	span.LogFields(
+		jlog.String("event", "http_trace"),
+		jlog.String("dns_lookup", trace.DNSLookup.String()),
+		jlog.String("connect", trace.Connect.String()),
+		jlog.String("tls_handshake", trace.TLSHandshake.String()),
+		jlog.String("time_to_first_byte", trace.TimeToFirstByte.String()),
+		jlog.Bool("conn_reused", trace.ConnReused),
+	)
+	if o, ok := c.metrics.(HTTPTraceObserver); ok {
+		o.ObserveHTTPTrace(operationName, trace)
+	}
+}
@@ -0,0 +1,60 @@
+package rm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type traceMetrics struct {
+	recordMetrics
+	traces []HTTPTrace
+}
+
+func (m *traceMetrics) ObserveHTTPTrace(operation string, trace HTTPTrace) {
+	m.traces = append(m.traces, trace)
+}
+
+func TestHTTPTrace(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	pk, _ := ioutil.ReadFile("../test/pk.pem")
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	metrics := new(traceMetrics)
+	client := NewClient(Config{
+		ClientID:        "xxx",
+		ClientSecret:    "xxx",
+		PrivateKey:      pk,
+		TokenSource:     oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "xxx"}),
+		OpenEndpoint:    srv.URL,
+		TLSConfig:       &tls.Config{RootCAs: pool},
+		Metrics:         metrics,
+		EnableHTTPTrace: true,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetStores(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Len(t, metrics.traces, 2)
+	require.False(t, metrics.traces[0].ConnReused)
+	require.Positive(t, metrics.traces[0].Connect)
+	require.Positive(t, metrics.traces[0].TLSHandshake)
+	require.Positive(t, metrics.traces[0].TimeToFirstByte)
+	// the connection is kept alive
+	require.True(t, metrics.traces[1].ConnReused)
+	require.Zero(t, metrics.traces[1].TLSHandshake)
+}
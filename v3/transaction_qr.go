@@ -15,20 +15,20 @@ const (
 // CreateTransactionQRRequest :
 type CreateTransactionQRRequest struct {
 	Type            CreateTransactionQRType `json:"type"`
-	CurrencyType    string                  `json:"currencyType"`
-	Amount          int                     `json:"amount"`
+	CurrencyType    CurrencyType            `json:"currencyType"`
+	Amount          Amount                  `json:"amount"`
 	IsPreFillAmount bool                    `json:"isPreFillAmount"`
-	Method          []string                `json:"method"`
+	Method          []PaymentMethod         `json:"method"`
 	Order           struct {
-		AdditionalData string `json:"additionalData"`
-		Details        string `json:"details"`
+		AdditionalData string `json:"additionalData,omitempty"`
+		Details        string `json:"details,omitempty"`
 		Title          string `json:"title"`
 	} `json:"order"`
 	Expiry struct {
 		Type string `json:"type"`
 	} `json:"expiry"`
-	RedirectURL string `json:"redirectUrl"`
-	StoreID     string `json:"storeId"`
+	RedirectURL string `json:"redirectUrl,omitempty"`
+	StoreID     string `json:"storeId,omitempty"`
 }
 
 // CreateTransactionQRResponse :
@@ -57,7 +57,7 @@ type CreateTransactionQRResponse struct {
 		Type            string      `json:"type"`
 		IsPreFillAmount bool        `json:"isPreFillAmount"`
 		CurrencyType    string      `json:"currencyType"`
-		Amount          int         `json:"amount"`
+		Amount          Amount      `json:"amount"`
 		Platform        string      `json:"platform"`
 		Method          interface{} `json:"method"`
 		Expiry          struct {
@@ -84,18 +84,24 @@ type CreateTransactionQRResponse struct {
 func (c *Client) CreateTransactionQR(
 	ctx context.Context,
 	req CreateTransactionQRRequest,
+	opts ...RequestOption,
 ) (*CreateTransactionQRResponse, error) {
+	o := newRequestOptions(opts)
 	if req.CurrencyType == "" {
-		req.CurrencyType = "MYR"
+		req.CurrencyType = CurrencyMYR
+	}
+	if req.StoreID == "" {
+		req.StoreID = c.storeIDOf(o)
 	}
 	resp := new(CreateTransactionQRResponse)
 	if err := c.do(
 		ctx,
 		"create_transaction_qrcode",
 		"post",
-		c.openEndpoint+"/v3/payment/transaction/qrcode",
+		c.openURL("/v3/payment/transaction/qrcode"),
 		req,
 		resp,
+		opts...,
 	); err != nil {
 		return nil, err
 	}
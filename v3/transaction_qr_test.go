@@ -0,0 +1,39 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransactionQR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/payment/transaction/qrcode", r.URL.Path)
+		require.Equal(t, "qr-key", r.Header.Get("Idempotency-Key"))
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "store-2", body["storeId"])
+		require.Equal(t, "MYR", body["currencyType"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"code":"qr-code"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var raw []byte
+	client := mockServerClient(srv)
+	resp, err := client.CreateTransactionQR(
+		context.Background(),
+		CreateTransactionQRRequest{},
+		WithStoreID("store-2"),
+		WithIdempotencyKey("qr-key"),
+		WithRawResponse(&raw),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "SUCCESS", resp.Code)
+	require.JSONEq(t, `{"item":{"code":"qr-code"},"code":"SUCCESS"}`, string(raw))
+}
@@ -0,0 +1,22 @@
+package rm
+
+import "net/http"
+
+// RoundTripperFunc : an adapter to use a function as http.RoundTripper,
+// it's useful to stub the responses of RM in test, e.g.
+//
+//	rm.Config{
+//		HTTPClient: &http.Client{
+//			Transport: rm.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+//				...
+//			}),
+//		},
+//	}
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+var _ http.RoundTripper = (RoundTripperFunc)(nil)
+
+// RoundTrip :
+func (fn RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
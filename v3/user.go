@@ -0,0 +1,57 @@
+package rm
+
+import (
+	"context"
+	"net/url"
+)
+
+// UserOptions :
+type UserOptions struct {
+	Offset int
+	Limit  int
+}
+
+// User : the loyalty member of the merchant
+type User struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	CountryCode string `json:"countryCode"`
+	PhoneNumber string `json:"phoneNumber"`
+	Gender      string `json:"gender"`
+	BirthDate   string `json:"birthDate"`
+	Tier        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"memberTier"`
+	LoyaltyPoint uint   `json:"loyaltyPoint"`
+	Status       string `json:"status"`
+	RegisteredAt Time   `json:"createdAt"`
+	UpdatedAt    Time   `json:"updatedAt"`
+}
+
+// ListUsers : returns a page of the loyalty members
+func (c *Client) ListUsers(ctx context.Context, opts UserOptions) ([]User, Pagination, error) {
+	items := make([]User, 0)
+	page, err := c.list(ctx, "list_users", c.openURL("/v3/users"), nil, opts.Offset, opts.Limit, &items)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return items, page, nil
+}
+
+// GetUser :
+func (c *Client) GetUser(ctx context.Context, userID string) (*User, error) {
+	item := new(User)
+	if _, err := c.doUnwrap(
+		ctx,
+		"get_user",
+		"get",
+		c.openURL("/v3/users/"+url.PathEscape(userID)),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,55 @@
+package rm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/v3/users":
+			require.Equal(t, "10", r.URL.Query().Get("offset"))
+			require.Equal(t, "5", r.URL.Query().Get("limit"))
+			w.Write([]byte(`{"items":[{"id":"1","name":"Alice","loyaltyPoint":100}],"code":"SUCCESS","meta":{"count":1,"total":11}}`))
+		case "/v3/users/a%2Fb":
+			w.Write([]byte(`{"item":{"id":"a/b","name":"Bob","email":"bob@example.com","countryCode":"60","phoneNumber":"123456789","memberTier":{"id":"tier-1","name":"Gold"},"loyaltyPoint":250,"status":"ACTIVE","createdAt":"2021-03-01T00:00:00Z"},"code":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":"NOT_FOUND","message":"User not found"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	users, page, err := client.ListUsers(ctx, UserOptions{Offset: 10, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, "Alice", users[0].Name)
+	require.Equal(t, uint(100), users[0].LoyaltyPoint)
+	require.Equal(t, Pagination{Offset: 10, Limit: 5, Total: 11}, page)
+
+	user, err := client.GetUser(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, "a/b", user.ID)
+	require.Equal(t, "bob@example.com", user.Email)
+	require.Equal(t, "60", user.CountryCode)
+	require.Equal(t, "Gold", user.Tier.Name)
+	require.Equal(t, uint(250), user.LoyaltyPoint)
+	require.Equal(t, "ACTIVE", user.Status)
+	require.Equal(t, 2021, user.RegisteredAt.Year())
+
+	_, err = client.GetUser(ctx, "2")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "NOT_FOUND", apiErr.Code)
+}
@@ -14,14 +14,22 @@ type (
 	PaymentType   string
 	PaymentMethod string
 	PaymentStatus string
+	CurrencyType  string
 )
 
 const (
+	// currency types :
+	CurrencyMYR CurrencyType = "MYR"
+	CurrencySGD CurrencyType = "SGD"
+	CurrencyCNY CurrencyType = "CNY"
+	CurrencyUSD CurrencyType = "USD"
+
 	// payment types :
 	PaymentTypeWeb    PaymentType = "WEB_PAYMENT"
 	PaymentTypeMobile PaymentType = "MOBILE_PAYMENT"
 
-	// payment methods :
+	// payment methods : the methods known to this package, the newer method
+	// of RM can still be passed as PaymentMethod("...") until it's added here
 	PaymentMethodWeChatMalaysia    PaymentMethod = "WECHAT_MY"
 	PaymentMethodWeChatChina       PaymentMethod = "WECHAT_CN"
 	PaymentMethodBoostMalaysia     PaymentMethod = "BOOST_MY"
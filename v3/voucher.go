@@ -0,0 +1,219 @@
+package rm
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// VoucherType :
+type VoucherType string
+
+// voucher types :
+const (
+	VoucherTypeCash     VoucherType = "CASH"
+	VoucherTypeDiscount VoucherType = "DISCOUNT"
+)
+
+// VoucherStatus :
+type VoucherStatus string
+
+// voucher status :
+const (
+	VoucherStatusActive   VoucherStatus = "ACTIVE"
+	VoucherStatusRedeemed VoucherStatus = "REDEEMED"
+	VoucherStatusExpired  VoucherStatus = "EXPIRED"
+)
+
+// CreateVoucherBatchRequest :
+type CreateVoucherBatchRequest struct {
+	Label string      `json:"label"`
+	Type  VoucherType `json:"type"`
+	// Amount is the value of the cash voucher
+	Amount uint `json:"amount,omitempty"`
+	// DiscountRate is the percentage of the discount voucher, 10 means 10%
+	DiscountRate uint `json:"discountRate,omitempty"`
+	MinimumSpend uint `json:"minimumSpendAmount,omitempty"`
+	Quantity     uint `json:"quantity"`
+	Expiry       struct {
+		Type      string     `json:"type"`
+		Day       int        `json:"day,omitempty"`
+		ExpiredAt *time.Time `json:"expiredAt,omitempty"`
+	} `json:"expiry"`
+}
+
+// VoucherBatch :
+type VoucherBatch struct {
+	Key          string      `json:"key"`
+	Label        string      `json:"label"`
+	Type         VoucherType `json:"type"`
+	Amount       uint        `json:"amount"`
+	DiscountRate uint        `json:"discountRate"`
+	MinimumSpend uint        `json:"minimumSpendAmount"`
+	Quantity     uint        `json:"quantity"`
+	UsedQuantity uint        `json:"usedQuantity"`
+	Status       string      `json:"status"`
+	CreatedAt    Time        `json:"createdAt"`
+	UpdatedAt    Time        `json:"updatedAt"`
+}
+
+// Voucher :
+type Voucher struct {
+	Code         string      `json:"code"`
+	Label        string      `json:"label"`
+	Type         VoucherType `json:"type"`
+	Amount       uint        `json:"amount"`
+	DiscountRate uint        `json:"discountRate"`
+	// Balance is the remaining value of the voucher
+	Balance    uint          `json:"balance"`
+	Status     VoucherStatus `json:"status"`
+	ExpiredAt  Time          `json:"expiredAt"`
+	RedeemedAt Time          `json:"redeemedAt"`
+	CreatedAt  Time          `json:"createdAt"`
+	UpdatedAt  Time          `json:"updatedAt"`
+}
+
+// Value : returns the amount of the cash voucher or the discount rate of the discount voucher
+func (v Voucher) Value() uint {
+	if v.Type == VoucherTypeDiscount {
+		return v.DiscountRate
+	}
+	return v.Amount
+}
+
+// VoucherResponse :
+type VoucherResponse struct {
+	Item Voucher `json:"item"`
+	Code string  `json:"code"`
+}
+
+// CreateVoucherBatch :
+func (c *Client) CreateVoucherBatch(
+	ctx context.Context,
+	req CreateVoucherBatchRequest,
+) (*VoucherBatch, error) {
+	item := new(VoucherBatch)
+	if _, err := c.doUnwrap(
+		ctx,
+		"create_voucher_batch",
+		"post",
+		c.openURL("/v3/voucher/batch"),
+		req,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RedeemVoucher : it returns ErrVoucherAlreadyRedeemed or ErrVoucherExpired if the voucher is no longer valid
+func (c *Client) RedeemVoucher(
+	ctx context.Context,
+	code string,
+) (*VoucherResponse, error) {
+	resp := new(VoucherResponse)
+	if err := c.do(
+		ctx,
+		"redeem_voucher",
+		"post",
+		c.openURL("/v3/voucher/"+url.PathEscape(code)+"/redeem"),
+		nil,
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VoidVoucher :
+func (c *Client) VoidVoucher(
+	ctx context.Context,
+	code string,
+) (*VoucherResponse, error) {
+	resp := new(VoucherResponse)
+	if err := c.do(
+		ctx,
+		"void_voucher",
+		"post",
+		c.openURL("/v3/voucher/"+url.PathEscape(code)+"/void"),
+		nil,
+		resp,
+	); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// VoidBatchResult :
+type VoidBatchResult struct {
+	BatchKey string `json:"key"`
+	// Voided is the number of unredeemed vouchers voided, the redeemed vouchers are kept
+	Voided          uint `json:"voidedQuantity"`
+	AlreadyRedeemed uint `json:"redeemedQuantity"`
+}
+
+// VoidVoucherBatch : voids all the unredeemed vouchers of the batch, e.g. to stop the campaign early
+func (c *Client) VoidVoucherBatch(
+	ctx context.Context,
+	batchID string,
+) (*VoidBatchResult, error) {
+	item := new(VoidBatchResult)
+	if _, err := c.doUnwrap(
+		ctx,
+		"void_voucher_batch",
+		"post",
+		c.openURL("/v3/voucher/batch/"+url.PathEscape(batchID)+"/void"),
+		nil,
+		item,
+	); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// VoucherListOptions :
+type VoucherListOptions struct {
+	Status VoucherStatus
+	// From and To filter the vouchers issued within the window, zero value means unbounded
+	From   time.Time
+	To     time.Time
+	Offset int
+	// Limit is the number of vouchers requested per page
+	Limit int
+}
+
+func (opts VoucherListOptions) values() url.Values {
+	params := url.Values{}
+	if opts.Status != "" {
+		params.Set("status", string(opts.Status))
+	}
+	if !opts.From.IsZero() {
+		params.Set("startAt", opts.From.UTC().Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		params.Set("endAt", opts.To.UTC().Format(time.RFC3339))
+	}
+	return params
+}
+
+// ListMemberVouchers : returns a page of the vouchers issued to the member
+func (c *Client) ListMemberVouchers(
+	ctx context.Context,
+	userID string,
+	opts VoucherListOptions,
+) ([]Voucher, Pagination, error) {
+	items := make([]Voucher, 0)
+	page, err := c.list(
+		ctx,
+		"list_member_vouchers",
+		c.openURL("/v3/loyalty/member/"+url.PathEscape(userID)+"/vouchers"),
+		opts.values(),
+		opts.Offset,
+		opts.Limit,
+		&items,
+	)
+	if err != nil {
+		return nil, Pagination{}, err
+	}
+	return items, page, nil
+}
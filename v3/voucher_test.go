@@ -0,0 +1,182 @@
+package rm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateVoucherBatch(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/voucher/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body = map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"key":"batch-1","label":"RM10 off","type":"CASH","amount":1000,"quantity":100,"status":"ACTIVE"},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	req := CreateVoucherBatchRequest{Label: "RM10 off", Type: VoucherTypeCash, Amount: 1000, Quantity: 100}
+	req.Expiry.Type = "DYNAMIC"
+	req.Expiry.Day = 30
+	batch, err := client.CreateVoucherBatch(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"label":    "RM10 off",
+		"type":     "CASH",
+		"amount":   float64(1000),
+		"quantity": float64(100),
+		"expiry":   map[string]interface{}{"type": "DYNAMIC", "day": float64(30)},
+	}, body)
+	require.Equal(t, &VoucherBatch{
+		Key:      "batch-1",
+		Label:    "RM10 off",
+		Type:     VoucherTypeCash,
+		Amount:   1000,
+		Quantity: 100,
+		Status:   "ACTIVE",
+	}, batch)
+
+	expiredAt := time.Date(2021, 12, 31, 16, 0, 0, 0, time.UTC)
+	req.Expiry.Type = "FIXED"
+	req.Expiry.Day = 0
+	req.Expiry.ExpiredAt = &expiredAt
+	_, err = client.CreateVoucherBatch(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"type": "FIXED", "expiredAt": "2021-12-31T16:00:00Z"}, body["expiry"])
+}
+
+func TestRedeemVoucher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/v3/voucher/a%2Fb/redeem":
+			w.Write([]byte(`{"item":{"code":"a/b","status":"REDEEMED"},"code":"SUCCESS"}`))
+		case "/v3/voucher/redeemed/redeem":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"VOUCHER_ALREADY_REDEEMED","message":"Voucher already redeemed"}}`))
+		case "/v3/voucher/expired/redeem":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"VOUCHER_EXPIRED","message":"Voucher expired"}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	resp, err := client.RedeemVoucher(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, "a/b", resp.Item.Code)
+	require.Equal(t, VoucherStatusRedeemed, resp.Item.Status)
+
+	_, err = client.RedeemVoucher(ctx, "redeemed")
+	require.ErrorIs(t, err, ErrVoucherAlreadyRedeemed)
+	_, err = client.RedeemVoucher(ctx, "expired")
+	require.ErrorIs(t, err, ErrVoucherExpired)
+}
+
+func TestVoidVoucher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/v3/voucher/a%2Fb/void":
+			w.Write([]byte(`{"item":{"code":"a/b"},"code":"SUCCESS"}`))
+		case "/v3/voucher/redeemed/void":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"VOUCHER_ALREADY_REDEEMED","message":"Voucher already redeemed"}}`))
+		case "/v3/voucher/expired/void":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"VOUCHER_EXPIRED","message":"Voucher expired"}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		ctx    = context.Background()
+		client = mockServerClient(srv)
+	)
+	resp, err := client.VoidVoucher(ctx, "a/b")
+	require.NoError(t, err)
+	require.Equal(t, "a/b", resp.Item.Code)
+
+	_, err = client.VoidVoucher(ctx, "redeemed")
+	require.ErrorIs(t, err, ErrVoucherAlreadyRedeemed)
+	_, err = client.VoidVoucher(ctx, "expired")
+	require.ErrorIs(t, err, ErrVoucherExpired)
+}
+
+func TestListMemberVouchers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/loyalty/member/123/vouchers" ||
+			q.Get("status") != "ACTIVE" ||
+			q.Get("startAt") != "2021-03-01T00:00:00Z" ||
+			q.Get("endAt") != "" ||
+			q.Get("limit") != "10" {
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[
+			{"code":"CASH10","label":"RM10 off","type":"CASH","amount":1000,"status":"ACTIVE","expiredAt":"2021-04-01T00:00:00Z"},
+			{"code":"DISC20","label":"20% off","type":"DISCOUNT","discountRate":20,"status":"ACTIVE","expiredAt":"2021-05-01T00:00:00Z"}
+		],"meta":{"count":2,"total":12},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	vouchers, page, err := mockServerClient(srv).ListMemberVouchers(context.Background(), "123", VoucherListOptions{
+		Status: VoucherStatusActive,
+		From:   time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, vouchers, 2)
+	require.Equal(t, uint(1000), vouchers[0].Value())
+	require.Equal(t, uint(20), vouchers[1].Value())
+	require.Equal(t, VoucherStatusActive, vouchers[1].Status)
+	require.Equal(t, time.May, vouchers[1].ExpiredAt.Month())
+	require.Equal(t, 12, page.Total)
+
+	_, ok := page.Next()
+	require.True(t, ok)
+}
+
+func TestVoidVoucherBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/voucher/batch/batch-1/void" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"item":{"key":"batch-1","voidedQuantity":80,"redeemedQuantity":20},"code":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	result, err := mockServerClient(srv).VoidVoucherBatch(context.Background(), "batch-1")
+	require.NoError(t, err)
+	require.Equal(t, &VoidBatchResult{BatchKey: "batch-1", Voided: 80, AlreadyRedeemed: 20}, result)
+}
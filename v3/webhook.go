@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"strings"
 	"time"
 )
 
@@ -15,51 +19,78 @@ const (
 )
 
 type Webhook struct {
-	Data struct {
-		BalanceAmount int       `json:"balanceAmount"`
-		CreatedAt     time.Time `json:"createdAt"`
-		CurrencyType  string    `json:"currencyType"`
-		Method        string    `json:"method"`
-		Order         struct {
-			Amount int    `json:"amount"`
-			Detail string `json:"detail"`
-			ID     string `json:"id"`
-			Title  string `json:"title"`
-		} `json:"order"`
-		Payee struct {
-		} `json:"payee"`
-		Platform    string `json:"platform"`
-		ReferenceID string `json:"referenceId"`
-		Region      string `json:"region"`
-		Status      string `json:"status"`
-		Store       struct {
-			AddressLine1 string    `json:"addressLine1"`
-			AddressLine2 string    `json:"addressLine2"`
-			City         string    `json:"city"`
-			Country      string    `json:"country"`
-			CountryCode  string    `json:"countryCode"`
-			CreatedAt    time.Time `json:"createdAt"`
-			GeoLocation  struct {
-				Latitude  float64 `json:"latitude"`
-				Longitude float64 `json:"longitude"`
-			} `json:"geoLocation"`
-			ID          string    `json:"id"`
-			ImageURL    string    `json:"imageUrl"`
-			Name        string    `json:"name"`
-			PhoneNumber string    `json:"phoneNumber"`
-			PostCode    string    `json:"postCode"`
-			State       string    `json:"state"`
-			Status      string    `json:"status"`
-			UpdatedAt   time.Time `json:"updatedAt"`
-		} `json:"store"`
-		TerminalID    string      `json:"terminalId"`
-		TransactionAt time.Time   `json:"transactionAt"`
-		TransactionID string      `json:"transactionId"`
-		Type          PaymentType `json:"type"`
-		UpdatedAt     time.Time   `json:"updatedAt"`
-		Voucher       interface{} `json:"voucher"`
-	} `json:"data"`
-	EventType eventType `json:"eventType"`
+	Data      PaymentEvent `json:"data"`
+	EventType eventType    `json:"eventType"`
+}
+
+// PaymentEvent :
+type PaymentEvent struct {
+	BalanceAmount Amount    `json:"balanceAmount"`
+	CreatedAt     time.Time `json:"createdAt"`
+	CurrencyType  string    `json:"currencyType"`
+	Method        string    `json:"method"`
+	Order         struct {
+		Amount Amount `json:"amount"`
+		Detail string `json:"detail"`
+		ID     string `json:"id"`
+		Title  string `json:"title"`
+	} `json:"order"`
+	Payee struct {
+	} `json:"payee"`
+	Platform    string `json:"platform"`
+	ReferenceID string `json:"referenceId"`
+	Region      string `json:"region"`
+	Status      string `json:"status"`
+	Store       struct {
+		AddressLine1 string    `json:"addressLine1"`
+		AddressLine2 string    `json:"addressLine2"`
+		City         string    `json:"city"`
+		Country      string    `json:"country"`
+		CountryCode  string    `json:"countryCode"`
+		CreatedAt    time.Time `json:"createdAt"`
+		GeoLocation  struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"geoLocation"`
+		ID          string    `json:"id"`
+		ImageURL    string    `json:"imageUrl"`
+		Name        string    `json:"name"`
+		PhoneNumber string    `json:"phoneNumber"`
+		PostCode    string    `json:"postCode"`
+		State       string    `json:"state"`
+		Status      string    `json:"status"`
+		UpdatedAt   time.Time `json:"updatedAt"`
+	} `json:"store"`
+	TerminalID    string      `json:"terminalId"`
+	TransactionAt time.Time   `json:"transactionAt"`
+	TransactionID string      `json:"transactionId"`
+	Type          PaymentType `json:"type"`
+	UpdatedAt     time.Time   `json:"updatedAt"`
+	Voucher       interface{} `json:"voucher"`
+}
+
+// VerifyWebhook : verify the signature of the webhook sent by RM using RM's public key
+func VerifyWebhook(publicKeyPEM []byte, header http.Header, body []byte) error {
+	pub, err := parsePublicKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	b64Str, err := encodeBody(body)
+	if err != nil {
+		return err
+	}
+
+	return verifySignature(header, pub, func(signType string) []string {
+		data := make([]string, 0, 4)
+		if b64Str != "" {
+			data = append(data, "data="+b64Str)
+		}
+		data = append(data, "nonceStr="+header.Get("X-Nonce-Str"))
+		data = append(data, "signType="+signType)
+		data = append(data, "timestamp="+header.Get("X-Timestamp"))
+		return data
+	})
 }
 
 // VerifyWebhook :
@@ -78,3 +109,40 @@ func (c *Client) VerifyWebhook(
 	}
 	return wh, nil
 }
+
+// WebhookEvent : the webhook event with the undecoded data, use the accessors
+// such as AsPayment to decode the data based on the event type
+type WebhookEvent struct {
+	Type eventType       `json:"eventType"`
+	Data json.RawMessage `json:"data"`
+}
+
+// DecodeWebhookEvent : decode the webhook body without decoding the data
+func DecodeWebhookEvent(body []byte) (*WebhookEvent, error) {
+	evt := new(WebhookEvent)
+	if err := unmarshalJSON(body, evt); err != nil {
+		return nil, err
+	}
+	if evt.Type == "" {
+		return nil, errors.New("rm: missing event type in webhook")
+	}
+	return evt, nil
+}
+
+// IsPayment : reports whether it's a payment event
+func (e WebhookEvent) IsPayment() bool {
+	return strings.HasPrefix(string(e.Type), "PAYMENT_")
+}
+
+// AsPayment : decode the data as payment event, it returns error if it's not a payment event
+func (e WebhookEvent) AsPayment() (*PaymentEvent, error) {
+	if !e.IsPayment() {
+		return nil, fmt.Errorf("rm: webhook event %q is not a payment event", e.Type)
+	}
+
+	pymt := new(PaymentEvent)
+	if err := unmarshalJSON(e.Data, pymt); err != nil {
+		return nil, err
+	}
+	return pymt, nil
+}
@@ -3,8 +3,11 @@ package rm
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"errors"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"testing"
 
@@ -23,7 +26,7 @@ func TestWebhook(t *testing.T) {
 	wh, err := client.VerifyWebhook(ctx, f)
 	require.NoError(t, err)
 
-	require.Equal(t, 2750, wh.Data.BalanceAmount)
+	require.Equal(t, Amount(2750), wh.Data.BalanceAmount)
 	require.Equal(t, "128200910090623482313", wh.Data.Order.ID)
 	require.Equal(t, "MYR", wh.Data.CurrencyType)
 	require.Equal(t, "2009106165088944", wh.Data.ReferenceID)
@@ -42,3 +45,56 @@ func TestWebhook(t *testing.T) {
 	_, err = client.VerifyWebhook(ctx, buf)
 	require.Error(t, err)
 }
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	pub, _ := ioutil.ReadFile("../test/pub.pem")
+	body, err := ioutil.ReadFile("./sample/webhook.json")
+	require.NoError(t, err)
+
+	client := mockRmClient()
+	b64Str, err := encodeBody(body)
+	require.NoError(t, err)
+
+	nonce, ts := "abcdefghijklmnopqrstuvwxy", "1630000000"
+	sign, err := signData(crypto.SHA256, []string{
+		"data=" + b64Str,
+		"nonceStr=" + nonce,
+		"signType=sha256",
+		"timestamp=" + ts,
+	}, client.pk)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Nonce-Str", nonce)
+	header.Set("X-Timestamp", ts)
+	header.Set("X-Signature", "sha256 "+sign)
+	require.NoError(t, VerifyWebhook(pub, header, body))
+
+	header.Set("X-Timestamp", "1630000001")
+	require.True(t, errors.Is(VerifyWebhook(pub, header, body), ErrSignatureMismatch))
+
+	require.Error(t, VerifyWebhook([]byte("invalid"), header, body))
+}
+
+func TestDecodeWebhookEvent(t *testing.T) {
+	body, err := ioutil.ReadFile("./sample/webhook.json")
+	require.NoError(t, err)
+
+	evt, err := DecodeWebhookEvent(body)
+	require.NoError(t, err)
+	require.Equal(t, EventTypeWebPayment, evt.Type)
+	require.True(t, evt.IsPayment())
+
+	pymt, err := evt.AsPayment()
+	require.NoError(t, err)
+	require.Equal(t, "128200910090623482313", pymt.Order.ID)
+	require.Equal(t, PaymentTypeWeb, pymt.Type)
+
+	evt, err = DecodeWebhookEvent([]byte(`{"eventType":"VOUCHER_REDEEMED","data":{}}`))
+	require.NoError(t, err)
+	_, err = evt.AsPayment()
+	require.Error(t, err)
+
+	_, err = DecodeWebhookEvent([]byte(`{"data":{}}`))
+	require.Error(t, err)
+}